@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/agent"
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentStaleAfter   time.Duration
+	agentVersionBelow string
+	agentStaleOnly    bool
+	agentYes          bool
+)
+
+// agentListRow is the JSON shape of `eg agent list`: the API response plus
+// the staleness computed client-side.
+type agentListRow struct {
+	agent.AgentResponse
+	Stale bool `json:"stale"`
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Manage registered eg-agent instances (admin)",
+	Long: `Inspect and deregister the eg-agent instances in your organization.
+
+An agent is considered stale when it has not heartbeated within --stale-after
+(agents heartbeat every 30s by default). All agent commands require
+organization admin rights.`,
+}
+
+var agentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered agents",
+	Example: `  eg agent list
+  eg agent list --stale
+  eg agent list --version-below 1.4.0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if agentVersionBelow != "" && !agent.IsVersion(agentVersionBelow) {
+			return fmt.Errorf("invalid --version-below %q: expected a version such as 1.4.0", agentVersionBelow)
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		agents, err := client.ListAgents()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var rows []agentListRow
+		for _, a := range agents {
+			if agentVersionBelow != "" && agent.CompareVersions(a.Version(), agentVersionBelow) >= 0 {
+				continue
+			}
+			stale := a.IsStale(now, agentStaleAfter)
+			if agentStaleOnly && !stale {
+				continue
+			}
+			rows = append(rows, agentListRow{AgentResponse: a, Stale: stale})
+		}
+
+		if output.Format == "json" {
+			if rows == nil {
+				rows = []agentListRow{}
+			}
+			output.PrintJSON(rows)
+			return nil
+		}
+
+		if len(rows) == 0 {
+			output.Info("No matching agents")
+			return nil
+		}
+
+		var table [][]string
+		for _, r := range rows {
+			table = append(table, []string{
+				output.ShortID(r.ID),
+				r.Name,
+				output.StatusColor(r.Status),
+				valueOrDash(r.Version()),
+				valueOrDash(stringValue(r.Hostname)),
+				heartbeatText(&r.AgentResponse, now, r.Stale),
+			})
+		}
+		output.PrintTable([]string{"ID", "NAME", "STATUS", "VERSION", "HOST", "LAST HEARTBEAT"}, table)
+		return nil
+	},
+}
+
+var agentGetCmd = &cobra.Command{
+	Use:   "get <name|id>",
+	Short: "Show an agent",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		a, err := resolveAgent(client, args[0])
+		if err != nil {
+			return err
+		}
+		a, err = client.GetAgent(a.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stale := a.IsStale(now, agentStaleAfter)
+		if output.Format == "json" {
+			output.PrintJSON(agentListRow{AgentResponse: *a, Stale: stale})
+			return nil
+		}
+
+		fmt.Printf("Agent %s\n", a.ID)
+		fmt.Printf("  Name:           %s\n", a.Name)
+		fmt.Printf("  Status:         %s\n", output.StatusColor(a.Status))
+		fmt.Printf("  Version:        %s\n", valueOrDash(a.Version()))
+		fmt.Printf("  Hostname:       %s\n", valueOrDash(stringValue(a.Hostname)))
+		fmt.Printf("  OS:             %s\n", valueOrDash(stringValue(a.OsInfo)))
+		fmt.Printf("  Last heartbeat: %s\n", heartbeatText(a, now, stale))
+		fmt.Printf("  Registered:     %s\n", output.FormatTime(a.CreatedAt))
+		return nil
+	},
+}
+
+var agentDeleteCmd = &cobra.Command{
+	Use:   "delete <name|id>",
+	Short: "Deregister an agent",
+	Long: `Deregister an agent. Its API key stops working immediately and any
+resources assigned to it can no longer be applied until reassigned.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		a, err := resolveAgent(client, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !agentYes {
+			ok, err := confirm(fmt.Sprintf("Delete agent %q (%s)?", a.Name, a.ID))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("aborted")
+			}
+		}
+
+		if err := client.DeleteAgent(a.ID); err != nil {
+			return err
+		}
+		output.Success("Agent %q deleted", a.Name)
+		return nil
+	},
+}
+
+// resolveAgent finds a single agent by exact name, ID or unique ID prefix.
+func resolveAgent(client *api.Client, input string) (*agent.AgentResponse, error) {
+	agents, err := client.ListAgents()
+	if err != nil {
+		return nil, err
+	}
+
+	lower := strings.ToLower(input)
+	var matches []*agent.AgentResponse
+	for i, a := range agents {
+		id := strings.ToLower(a.ID)
+		if id == lower || strings.EqualFold(a.Name, input) {
+			return &agents[i], nil
+		}
+		if strings.HasPrefix(id, lower) {
+			matches = append(matches, &agents[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("agent '%s' not found. Run: eg agent list", input)
+	case 1:
+		return matches[0], nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "'%s' matches %d agents:", input, len(matches))
+	for _, a := range matches {
+		fmt.Fprintf(&b, "\n  %s  %s", a.ID, a.Name)
+	}
+	b.WriteString("\nUse a longer prefix or the agent name.")
+	return nil, fmt.Errorf("%s", b.String())
+}
+
+// heartbeatText renders how long ago the agent checked in, in red when the
+// agent is stale.
+func heartbeatText(a *agent.AgentResponse, now time.Time, stale bool) string {
+	text := "never"
+	if t, ok := a.LastHeartbeat(); ok {
+		text = output.FormatSpan(now.Sub(t)) + " ago"
+	}
+	if stale {
+		return color.RedString(text + " (stale)")
+	}
+	return text
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func init() {
+	agentCmd.PersistentFlags().DurationVar(&agentStaleAfter, "stale-after", 2*time.Minute, "Flag agents that have not heartbeated within this long")
+	agentListCmd.Flags().StringVar(&agentVersionBelow, "version-below", "", "Only show agents running a version older than this (e.g. 1.4.0)")
+	agentListCmd.Flags().BoolVar(&agentStaleOnly, "stale", false, "Only show stale agents")
+	agentDeleteCmd.Flags().BoolVarP(&agentYes, "yes", "y", false, "Do not ask for confirmation")
+
+	agentCmd.AddCommand(agentListCmd)
+	agentCmd.AddCommand(agentGetCmd)
+	agentCmd.AddCommand(agentDeleteCmd)
+	rootCmd.AddCommand(agentCmd)
+}
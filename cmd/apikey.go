@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apikeyName    string
+	apikeyScopes  []string
+	apikeyExpires string
+	apikeyYes     bool
+)
+
+var apikeyCmd = &cobra.Command{
+	Use:     "apikey",
+	Aliases: []string{"apikeys"},
+	Short:   "Manage API keys",
+	Long: `Create, list and revoke API keys for your user.
+
+Keys with the agent:connect scope are what eg-agent uses to connect; keys
+without scopes act as you, e.g. for CI. The secret is only shown once, when
+the key is created.`,
+}
+
+var apikeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key",
+	Example: `  eg apikey create --name host1 --scope agent:connect --expires 90d
+  eg apikey create --name ci --expires 30d --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if apikeyName == "" {
+			return fmt.Errorf("--name is required")
+		}
+		expiresAt, err := apikeyExpiry(apikeyExpires)
+		if err != nil {
+			return err
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		key, err := client.CreateApiKey(&api.CreateApiKeyRequest{
+			Name:      apikeyName,
+			Scopes:    apikeyScopes,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if output.Format == "json" {
+			output.PrintJSON(key)
+			return nil
+		}
+
+		output.Success("API key %q created (id: %s)", key.Name, key.ID)
+		fmt.Printf("\n  %s\n\n", key.Key)
+		output.Info("Store this key now; it will not be shown again.")
+		return nil
+	},
+}
+
+var apikeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		client, err := newClient(resolveProfileName(), profile)
+		if err != nil {
+			return err
+		}
+
+		keys, err := client.ListApiKeys()
+		if err != nil {
+			return err
+		}
+
+		if output.Format == "json" {
+			output.PrintJSON(keys)
+			return nil
+		}
+
+		var rows [][]string
+		for _, k := range keys {
+			name := k.Name
+			if k.Matches(profile.APIKey) {
+				name += " *"
+			}
+			rows = append(rows, []string{
+				output.ShortID(k.ID),
+				name,
+				k.KeyPrefix + "…",
+				valueOrDash(strings.Join(k.Scopes, ",")),
+				output.FormatTime(k.CreatedAt),
+				output.FormatTime(k.ExpiresAt),
+				output.FormatTime(k.LastUsedAt),
+			})
+		}
+		output.PrintTable([]string{"ID", "NAME", "PREFIX", "SCOPES", "CREATED", "EXPIRES", "LAST USED"}, rows)
+		fmt.Println("\n* = key used by this profile")
+		return nil
+	},
+}
+
+var apikeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id|name|prefix>",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		client, err := newClient(resolveProfileName(), profile)
+		if err != nil {
+			return err
+		}
+
+		key, err := resolveApiKey(client, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !apikeyYes {
+			prompt := fmt.Sprintf("Revoke API key %q (%s)?", key.Name, key.KeyPrefix)
+			if key.Matches(profile.APIKey) {
+				prompt = fmt.Sprintf("API key %q is the key this profile uses; the profile will stop working. Revoke it?", key.Name)
+			}
+			ok, err := confirm(prompt)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("aborted")
+			}
+		}
+
+		if err := client.RevokeApiKey(key.ID); err != nil {
+			return err
+		}
+		output.Success("API key %q revoked", key.Name)
+		return nil
+	},
+}
+
+// resolveApiKey finds a single key by ID, exact name or key prefix.
+func resolveApiKey(client *api.Client, input string) (*api.ApiKey, error) {
+	keys, err := client.ListApiKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*api.ApiKey
+	for i, k := range keys {
+		if k.ID == input || k.KeyPrefix == input {
+			return &keys[i], nil
+		}
+		if k.Name == input || strings.HasPrefix(k.ID, input) {
+			matches = append(matches, &keys[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("API key '%s' not found. Run: eg apikey list", input)
+	case 1:
+		return matches[0], nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "'%s' matches %d API keys:", input, len(matches))
+	for _, k := range matches {
+		fmt.Fprintf(&b, "\n  %s  %s  %s…", k.ID, k.Name, k.KeyPrefix)
+	}
+	b.WriteString("\nUse the key ID or prefix instead.")
+	return nil, fmt.Errorf("%s", b.String())
+}
+
+// apikeyExpiry turns an --expires value such as "90d" into an RFC3339
+// timestamp. An empty value means the key does not expire.
+func apikeyExpiry(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	// duration.Parse reads a bare number as hours, which is easy to get
+	// wrong for a credential lifetime.
+	if _, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		return "", fmt.Errorf("--expires needs a unit, e.g. 90d")
+	}
+	d, err := duration.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid --expires: %w", err)
+	}
+	if d <= 0 {
+		return "", fmt.Errorf("--expires must be positive")
+	}
+	return time.Now().Add(d).UTC().Format(time.RFC3339), nil
+}
+
+func init() {
+	apikeyCreateCmd.Flags().StringVar(&apikeyName, "name", "", "Key name, e.g. the host or pipeline using it")
+	apikeyCreateCmd.Flags().StringArrayVar(&apikeyScopes, "scope", nil, "Scope to grant, e.g. agent:connect (repeatable)")
+	apikeyCreateCmd.Flags().StringVar(&apikeyExpires, "expires", "", "Expire the key after this long, e.g. 90d (default: never)")
+	apikeyCreateCmd.RegisterFlagCompletionFunc("scope", cobra.FixedCompletions([]string{api.ScopeAgentConnect}, cobra.ShellCompDirectiveNoFileComp))
+	apikeyRevokeCmd.Flags().BoolVarP(&apikeyYes, "yes", "y", false, "Do not ask for confirmation")
+
+	apikeyCmd.AddCommand(apikeyCreateCmd)
+	apikeyCmd.AddCommand(apikeyListCmd)
+	apikeyCmd.AddCommand(apikeyRevokeCmd)
+	rootCmd.AddCommand(apikeyCmd)
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince    string
+	auditUntil    string
+	auditActor    string
+	auditAction   string
+	auditResource string
+	auditFormat   string
+	auditLimit    int
+	auditPage     int
+	auditPageSize int
+
+	auditTailSince string
+	auditFollow    bool
+	auditLines     int
+	auditInterval  time.Duration
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "View the organization audit log (admin)",
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit events, newest first",
+	Long: `List audit events, newest first.
+
+--since and --until accept a look-back duration (7d, 12h), a date (2024-05-01)
+or an RFC 3339 timestamp. By default every matching page is fetched; use
+--page to fetch a single page or --limit to stop after N events.`,
+	Example: `  eg audit list --since 24h --action SESSION_STARTED
+  eg audit list --actor alice@example.com --format csv > alice.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := auditFormat
+		if format == "" {
+			format = output.Format
+		}
+		if format != "table" && format != "json" && format != "csv" {
+			return fmt.Errorf("unsupported format %q (use table, json or csv)", format)
+		}
+
+		q, err := auditQuery(auditSince, auditUntil)
+		if err != nil {
+			return err
+		}
+		q.Size = auditPageSize
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		var events []api.AuditEvent
+		var page *api.AuditPage
+		if cmd.Flags().Changed("page") {
+			if auditPage < 1 {
+				return fmt.Errorf("--page starts at 1")
+			}
+			q.Page = auditPage - 1
+			page, err = client.ListAuditEvents(q)
+			if err != nil {
+				return err
+			}
+			events = page.Content
+		} else {
+			events, err = client.ListAllAuditEvents(q, auditLimit)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch format {
+		case "json":
+			if events == nil {
+				events = []api.AuditEvent{}
+			}
+			output.PrintJSON(events)
+		case "csv":
+			return writeAuditCSV(events)
+		default:
+			if len(events) == 0 {
+				output.Info("No audit events found")
+				return nil
+			}
+			var rows [][]string
+			for _, e := range events {
+				rows = append(rows, []string{
+					output.FormatTime(e.Timestamp),
+					valueOrDash(e.ActorEmail),
+					e.Action,
+					auditTarget(&e),
+					valueOrDash(e.IpAddress),
+				})
+			}
+			output.PrintTable([]string{"TIME", "ACTOR", "ACTION", "RESOURCE", "IP"}, rows)
+			if page != nil && page.TotalPages > 0 {
+				fmt.Printf("\nPage %d of %d (%d events)\n", page.Number+1, page.TotalPages, page.TotalElements)
+			}
+		}
+		return nil
+	},
+}
+
+var auditTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show the latest audit events, optionally following new ones",
+	Long: `Show the latest audit events, oldest first. With -f, keep polling and print
+new events as they arrive until interrupted.
+
+With -o json each event is written as a single JSON line, suitable for piping
+into jq or a log shipper.`,
+	Example: `  eg audit tail -f
+  eg audit tail -f -o json --action SESSION_STARTED | jq .actorEmail`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditInterval < time.Second {
+			return fmt.Errorf("--interval must be at least 1s")
+		}
+		q, err := auditQuery(auditTailSince, "")
+		if err != nil {
+			return err
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		emit := func(events []api.AuditEvent) {
+			for _, e := range events {
+				if output.Format == "json" {
+					enc.Encode(e)
+				} else {
+					printAuditLine(&e)
+				}
+			}
+		}
+
+		cursor := &api.AuditCursor{Since: q.Since}
+		if auditLines > 0 {
+			first := q
+			first.Size = auditLines
+			page, err := client.ListAuditEvents(first)
+			if err != nil {
+				return err
+			}
+			emit(cursor.Advance(page.Content))
+		}
+		if !auditFollow {
+			return nil
+		}
+		if cursor.Since.IsZero() {
+			// Start from the newest event the server has rather than the
+			// local clock, which may be behind the server's.
+			page, err := client.ListAuditEvents(api.AuditQuery{Size: 1})
+			if err != nil {
+				return err
+			}
+			cursor.Advance(page.Content)
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		ticker := time.NewTicker(auditInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-sigCh:
+				return nil
+			}
+
+			q.Since = cursor.Since
+			events, err := client.ListAllAuditEvents(q, 0)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "poll failed: %v\n", err)
+				continue
+			}
+			emit(cursor.Advance(events))
+		}
+	},
+}
+
+// auditQuery builds the query shared by list and tail from the filter flags.
+func auditQuery(since, until string) (api.AuditQuery, error) {
+	q := api.AuditQuery{
+		Actor:    auditActor,
+		Action:   strings.ToUpper(auditAction),
+		Resource: auditResource,
+	}
+	now := time.Now()
+	if since != "" {
+		t, err := duration.ParseSince(since, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid --since: %w", err)
+		}
+		q.Since = t
+	}
+	if until != "" {
+		t, err := duration.ParseSince(until, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid --until: %w", err)
+		}
+		q.Until = t
+	}
+	return q, nil
+}
+
+func auditTarget(e *api.AuditEvent) string {
+	switch {
+	case e.ResourceName != "":
+		return e.ResourceName
+	case e.ResourceID != "":
+		return e.ResourceType + " " + output.ShortID(e.ResourceID)
+	default:
+		return valueOrDash(e.ResourceType)
+	}
+}
+
+func printAuditLine(e *api.AuditEvent) {
+	fmt.Printf("%s  %-24s  %-24s  %s", output.FormatTime(e.Timestamp), valueOrDash(e.ActorEmail), e.Action, auditTarget(e))
+	if e.IpAddress != "" {
+		fmt.Printf("  from %s", e.IpAddress)
+	}
+	fmt.Println()
+}
+
+func writeAuditCSV(events []api.AuditEvent) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{
+		"id", "timestamp", "actor_id", "actor_email", "action",
+		"resource_type", "resource_id", "resource_name", "ip_address", "details",
+	})
+	for _, e := range events {
+		details := ""
+		if len(e.Details) > 0 {
+			b, _ := json.Marshal(e.Details)
+			details = string(b)
+		}
+		w.Write([]string{
+			e.ID, e.Timestamp, e.ActorID, e.ActorEmail, e.Action,
+			e.ResourceType, e.ResourceID, e.ResourceName, e.IpAddress, details,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func init() {
+	for _, c := range []*cobra.Command{auditListCmd, auditTailCmd} {
+		c.Flags().StringVar(&auditActor, "actor", "", "Only events by this user (email or ID)")
+		c.Flags().StringVar(&auditAction, "action", "", "Only events with this action, e.g. SESSION_STARTED")
+		c.Flags().StringVar(&auditResource, "resource", "", "Only events about this resource (name or ID)")
+		c.RegisterFlagCompletionFunc("resource", completeResourceNames)
+	}
+
+	auditListCmd.Flags().StringVar(&auditSince, "since", "7d", "How far back to go (e.g. 7d, 12h, 2024-05-01)")
+	auditListCmd.Flags().StringVar(&auditUntil, "until", "", "Only events before this time")
+	auditListCmd.Flags().StringVar(&auditFormat, "format", "", "Output format: table, json or csv (defaults to --output)")
+	auditListCmd.Flags().IntVar(&auditLimit, "limit", 0, "Maximum number of events to fetch (0 = all)")
+	auditListCmd.Flags().IntVar(&auditPage, "page", 1, "Fetch only this page (1-based)")
+	auditListCmd.Flags().IntVar(&auditPageSize, "page-size", 100, "Events per page")
+
+	auditTailCmd.Flags().StringVar(&auditTailSince, "since", "", "Only show events after this time (e.g. 1h, 2024-05-01)")
+	auditTailCmd.Flags().BoolVarP(&auditFollow, "follow", "f", false, "Keep polling for new events")
+	auditTailCmd.Flags().IntVarP(&auditLines, "lines", "n", 10, "Number of recent events to show first")
+	auditTailCmd.Flags().DurationVar(&auditInterval, "interval", 5*time.Second, "Polling interval with --follow")
+
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditTailCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/cache"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// completionTTL bounds how stale cached data may be when completing. It is
+// short because completions are only a hint, but long enough that pressing
+// TAB repeatedly doesn't call the API every time.
+const completionTTL = 30 * time.Second
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// firstArgOnly restricts a completion function to the first positional argument.
+func firstArgOnly(fn completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completeSessionIDs suggests active session ID prefixes, described by
+// status, IP and time remaining. The short form is offered until more of
+// the ID has been typed than it holds; then the full ID is.
+func completeSessionIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	sessions := cachedActiveSessions()
+	var completions []string
+	for _, s := range sessions {
+		if !strings.HasPrefix(strings.ToLower(s.ID), strings.ToLower(toComplete)) {
+			continue
+		}
+		id := output.ShortID(s.ID)
+		if len(toComplete) > len(id) {
+			id = s.ID
+		}
+		ip := s.Ipv4Address
+		if ip == "" {
+			ip = s.Ipv6Address
+		}
+		desc := fmt.Sprintf("%s %s", s.Status, ip)
+		if d, ok := s.Remaining(time.Now()); ok && d > 0 {
+			desc += ", expires in " + formatCompactSpan(d)
+		}
+		completions = append(completions, id+"\t"+desc)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileNames suggests configured profile names.
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for name, p := range cfg.Profiles {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+p.APIURL)
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeResourceNames provides shell completion for resource name flags.
+func completeResourceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, r := range cachedResources() {
+		if strings.HasPrefix(strings.ToLower(r.Name), strings.ToLower(toComplete)) {
+			completions = append(completions, r.Name+"\t"+r.ResourceType)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedActiveSessions returns active sessions from the local cache, fetching
+// (and re-caching) them if the cache is missing or older than completionTTL.
+func cachedActiveSessions() []api.Session {
+	name := resolveProfileName()
+	if name == "" {
+		return nil
+	}
+	if state, err := cache.ReadSessions(name); err == nil && state.Age(time.Now()) < completionTTL {
+		return state.Active(time.Now())
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil
+	}
+	if _, err := listSessions(client); err != nil {
+		return nil
+	}
+	state, err := cache.ReadSessions(name)
+	if err != nil {
+		return nil
+	}
+	return state.Active(time.Now())
+}
+
+// cachedResources returns the resource list from the local cache, fetching
+// it if the cache is missing or older than completionTTL.
+func cachedResources() []api.Resource {
+	name := resolveProfileName()
+	if name == "" {
+		return nil
+	}
+	if state, err := cache.ReadResources(name); err == nil && state.Age(time.Now()) < completionTTL {
+		return state.Resources
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil
+	}
+	resources, err := client.ListResources()
+	if err != nil {
+		return nil
+	}
+	cache.WriteResources(name, resources)
+	return resources
+}
+
+// formatCompactSpan renders a duration without spaces, e.g. "1h12m".
+func formatCompactSpan(d time.Duration) string {
+	return strings.ReplaceAll(output.FormatSpan(d), " ", "")
+}
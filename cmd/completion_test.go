@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/cache"
+)
+
+func TestCompleteSessionIDs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	oldAll, oldProfile := allProfilesFlag, profileFlag
+	allProfilesFlag, profileFlag = false, "prod"
+	defer func() { allProfilesFlag, profileFlag = oldAll, oldProfile }()
+
+	expires := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	err := cache.WriteSessions("prod", []api.Session{
+		{ID: "3f2a9c1e-0000-4000-8000-000000000001", Status: "ACTIVE", ExpiresAt: expires},
+		{ID: "3f2a9c1e-0000-4000-8000-000000000002", Status: "ACTIVE", ExpiresAt: expires},
+		{ID: "b7d41e60-0000-4000-8000-000000000003", Status: "ACTIVE", ExpiresAt: expires},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		toComplete string
+		want       []string
+	}{
+		{"", []string{"3f2a9c1e", "3f2a9c1e", "b7d41e60"}},
+		{"b7", []string{"b7d41e60"}},
+		{"B7D4", []string{"b7d41e60"}},
+		{"3f2a9c1e", []string{"3f2a9c1e", "3f2a9c1e"}},
+		{"3f2a9c1e-", []string{"3f2a9c1e-0000-4000-8000-000000000001", "3f2a9c1e-0000-4000-8000-000000000002"}},
+		{"3F2A9C1E-0000-4000-8000-000000000002", []string{"3f2a9c1e-0000-4000-8000-000000000002"}},
+		{"c0", nil},
+	}
+	for _, tt := range tests {
+		got, _ := completeSessionIDs(nil, nil, tt.toComplete)
+		var ids []string
+		for _, c := range got {
+			ids = append(ids, strings.SplitN(c, "\t", 2)[0])
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%q: got %v, want %v", tt.toComplete, ids, tt.want)
+		}
+	}
+}
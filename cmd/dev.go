@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/agent"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	devAddr         string
+	devAPIKey       string
+	devScriptDir    string
+	devWriteProfile string
+	devAgentConfig  string
+	devQuiet        bool
+	devForce        bool
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Tools for developing against EntryGuard locally",
+}
+
+var devServerCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Run an in-memory mock EntryGuard API",
+	Long: `Run a mock EntryGuard API with in-memory state, for offline development
+and integration tests of eg and eg-agent.
+
+It serves the user API (/auth/me, /detect-ip, /sessions, resources, API
+keys, agents, audit logs) and the agent API (/agents/register, heartbeat,
+commands/poll and command results). Sessions on agent-managed resources
+are turned into APPLY commands for the agent, and into REVOKE commands when
+they are stopped or expire.
+
+Resources "dev-sg" and "dev-tunnel" are always created and apply
+instantly; rules on "dev-tunnel" are given a tunnel port on the server's
+host, though nothing listens on it. With --script-dir, an agent-managed
+resource "dev-agent" running the scripts in that directory is created
+too, served to whichever agent polls first.
+
+--write-profile and --agent-config point eg and eg-agent at the server:
+
+  eg dev server --script-dir ./scripts --write-profile dev --agent-config ./agent.yml
+  eg-agent run -c ./agent.yml
+  eg --profile dev session start
+
+--write-profile refuses to replace a profile that points at anything but a
+local server unless --force is given.
+
+State is lost when the server stops. Use --addr 127.0.0.1:0 to pick a free
+port; the URL is printed on startup.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backend := entryguardtest.NewBackend()
+		backend.APIKey = devAPIKey
+		backend.AddResource(entryguard.Resource{
+			Name:         "dev-sg",
+			ResourceType: "AWS_SECURITY_GROUP",
+			Description:  "Mock resource, applied instantly",
+			Enabled:      true,
+		})
+		backend.AddResource(entryguard.Resource{
+			Name:         "dev-tunnel",
+			ResourceType: "TUNNEL",
+			Description:  "Mock tunnel resource, applied instantly",
+			Enabled:      true,
+		})
+		if devScriptDir != "" {
+			dir, err := filepath.Abs(devScriptDir)
+			if err != nil {
+				return err
+			}
+			backend.AddResource(entryguard.Resource{
+				Name:         "dev-agent",
+				ResourceType: "AGENT",
+				Description:  "Applied by eg-agent",
+				ScriptDir:    dir,
+				Enabled:      true,
+			})
+		}
+
+		ln, err := net.Listen("tcp", devAddr)
+		if err != nil {
+			return fmt.Errorf("cannot listen on %s: %w", devAddr, err)
+		}
+		apiURL := fmt.Sprintf("http://%s/api/v1", ln.Addr())
+		backend.TunnelHost, _, _ = net.SplitHostPort(ln.Addr().String())
+
+		if devWriteProfile != "" {
+			if err := writeDevProfile(devWriteProfile, apiURL); err != nil {
+				ln.Close()
+				return err
+			}
+		}
+		if devAgentConfig != "" {
+			if err := writeDevAgentConfig(devAgentConfig, apiURL); err != nil {
+				ln.Close()
+				return err
+			}
+		}
+
+		var handler http.Handler = backend
+		if !devQuiet {
+			handler = logRequests(backend)
+		}
+		srv := &http.Server{Handler: handler}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdownCtx)
+		}()
+
+		output.Info("Mock EntryGuard API listening on %s", apiURL)
+		if devAPIKey != "" {
+			output.Info("API key: %s", devAPIKey)
+		} else {
+			output.Info("Accepting any API key")
+		}
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// writeDevProfile saves a profile for the mock server. An existing profile
+// of the same name is only replaced if it already points at a loopback
+// address, or with --force, so a real profile's API key is not lost.
+func writeDevProfile(name, apiURL string) error {
+	key := devAPIKey
+	if key == "" {
+		key = entryguardtest.APIKey
+	}
+	err := config.Update(func(cfg *config.Config) error {
+		if p, ok := cfg.Profiles[name]; ok && !devForce && !isLoopbackURL(p.APIURL) {
+			return fmt.Errorf("profile %q already points at %s; use another --write-profile name, or --force to replace it", name, p.APIURL)
+		}
+		config.AddProfile(cfg, name, config.Profile{APIKey: key, APIURL: apiURL})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	output.Success("Profile %q points at the mock server", name)
+	return nil
+}
+
+// isLoopbackURL reports whether rawURL points at localhost or a loopback
+// address.
+func isLoopbackURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && addr.IsLoopback()
+}
+
+func writeDevAgentConfig(path, apiURL string) error {
+	key := devAPIKey
+	if key == "" {
+		key = entryguardtest.APIKey
+	}
+	cfg := &agent.Config{
+		Server: agent.ServerConfig{URL: apiURL, APIKey: key},
+		Agent: agent.AgentConfig{
+			Name:              "dev-agent",
+			PollInterval:      time.Second,
+			HeartbeatInterval: 10 * time.Second,
+		},
+	}
+	if err := agent.WriteConfig(path, cfg); err != nil {
+		return err
+	}
+	output.Success("eg-agent config written to %s", path)
+	return nil
+}
+
+// statusRecorder captures the response status for request logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	logger := log.New(os.Stderr, "", log.Ltime)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		logger.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
+
+func init() {
+	devServerCmd.Flags().StringVar(&devAddr, "addr", "127.0.0.1:8787", "Address to listen on")
+	devServerCmd.Flags().StringVar(&devAPIKey, "api-key", entryguardtest.APIKey, "API key to accept (empty accepts any)")
+	devServerCmd.Flags().StringVar(&devScriptDir, "script-dir", "", "Create an agent-managed resource running the scripts in this directory")
+	devServerCmd.Flags().StringVar(&devWriteProfile, "write-profile", "", "Save an eg profile with this name pointing at the server")
+	devServerCmd.Flags().StringVar(&devAgentConfig, "agent-config", "", "Write an eg-agent config file pointing at the server")
+	devServerCmd.Flags().BoolVarP(&devQuiet, "quiet", "q", false, "Do not log requests")
+	devServerCmd.Flags().BoolVar(&devForce, "force", false, "Let --write-profile replace a profile that points at another server")
+
+	devCmd.AddCommand(devServerCmd)
+	rootCmd.AddCommand(devCmd)
+}
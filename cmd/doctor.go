@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/doctor"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorTimeout time.Duration
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration and connectivity problems",
+	Long: `Run a checklist of configuration and connectivity checks for the current
+profile: config file permissions, profile and connection settings (ca_file,
+client certificate, proxy), DNS and TLS to the API, clock skew, API key
+validity and reachability of the IP detection service over IPv4 and IPv6.
+
+Exits with status 1 if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if doctorTimeout <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
+		var results []doctor.Result
+		report := func(r doctor.Result) {
+			results = append(results, r)
+			if output.Format != "json" {
+				printDoctorResult(r)
+			}
+		}
+		withTimeout := func(check func(ctx context.Context) doctor.Result) doctor.Result {
+			ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+			defer cancel()
+			return check(ctx)
+		}
+
+		if path, err := config.Path(); err == nil {
+			report(doctor.CheckConfigFile(path))
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		profile, name, apiURL := doctorProfile(report)
+		if apiURL != nil {
+			connResult, tr := doctor.CheckTransport(transportConfig(profile))
+			report(connResult)
+			if tr == nil {
+				apiURL = nil
+			} else {
+				transport = tr
+			}
+		}
+		if apiURL != nil {
+			proxyResult, proxy := doctor.CheckProxy(apiURL, transport, profile.ProxyURL != "")
+			report(proxyResult)
+
+			reachable := true
+			if proxy != nil {
+				// DNS and TLS are the proxy's business; the clock and API key
+				// checks below still go through it.
+				report(doctor.Result{Name: "DNS", Status: doctor.Skip, Detail: "resolved by the proxy"})
+				report(doctor.Result{Name: "TLS", Status: doctor.Skip, Detail: "handshake goes through the proxy"})
+			} else {
+				dns := withTimeout(func(ctx context.Context) doctor.Result { return doctor.CheckDNS(ctx, apiURL.Hostname()) })
+				report(dns)
+				if dns.Status == doctor.Fail {
+					reachable = false
+					report(doctor.Result{Name: "TLS", Status: doctor.Skip, Detail: "DNS lookup failed"})
+				} else {
+					tlsResult := withTimeout(func(ctx context.Context) doctor.Result {
+						return doctor.CheckTLS(ctx, apiURL, transport.TLSClientConfig, time.Now())
+					})
+					report(tlsResult)
+					reachable = tlsResult.Status != doctor.Fail
+				}
+			}
+
+			if reachable {
+				httpClient := &http.Client{Transport: transport, Timeout: doctorTimeout}
+				report(withTimeout(func(ctx context.Context) doctor.Result {
+					return doctor.CheckClock(ctx, httpClient, profile.APIURL, time.Now)
+				}))
+				client, err := newClient(name, profile)
+				if err != nil {
+					return err
+				}
+				client.HTTPClient.Timeout = doctorTimeout
+				report(doctor.CheckAPIKey(client, profile.UsesLogin()))
+			} else {
+				report(doctor.Result{Name: "Clock", Status: doctor.Skip, Detail: "API not reachable"})
+				report(doctor.Result{Name: "API key", Status: doctor.Skip, Detail: "API not reachable"})
+			}
+		} else if name != "" {
+			report(doctor.Result{Name: "API", Status: doctor.Skip, Detail: "profile settings are invalid"})
+		}
+
+		report(withTimeout(func(ctx context.Context) doctor.Result {
+			return doctor.CheckIPProvider(ctx, "IPv4 detection", api.IPv4DetectURL, "tcp4", transport)
+		}))
+		report(withTimeout(func(ctx context.Context) doctor.Result {
+			return doctor.CheckIPProvider(ctx, "IPv6 detection", api.IPv6DetectURL, "tcp6", transport)
+		}))
+
+		failed, warned := 0, 0
+		for _, r := range results {
+			switch r.Status {
+			case doctor.Fail:
+				failed++
+			case doctor.Warn:
+				warned++
+			}
+		}
+
+		if output.Format == "json" {
+			output.PrintJSON(map[string]any{
+				"profile": name,
+				"checks":  results,
+				"ok":      failed == 0,
+			})
+		} else {
+			fmt.Println()
+			switch {
+			case failed > 0:
+				output.Error("%d check(s) failed, %d warning(s)", failed, warned)
+			case warned > 0:
+				output.Info("All checks passed with %d warning(s)", warned)
+			default:
+				output.Success("All checks passed")
+			}
+		}
+
+		if failed > 0 {
+			return &exitError{code: 1}
+		}
+		return nil
+	},
+}
+
+// doctorProfile reports on the profile in use and returns it with its parsed
+// API URL, or a nil URL when the profile is missing or unusable.
+func doctorProfile(report func(doctor.Result)) (*config.Profile, string, *url.URL) {
+	fail := func(detail, hint string) {
+		report(doctor.Result{Name: "Profile", Status: doctor.Fail, Detail: detail, Hint: hint})
+	}
+
+	if isMultiProfile() {
+		fail("eg doctor checks one profile at a time", "Run: eg doctor --profile <name>")
+		return nil, "", nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fail(err.Error(), "Fix the syntax error in the config file, or move it aside and run: eg profile add <name>")
+		return nil, "", nil
+	}
+	name := resolveProfileName()
+	profile, err := config.GetProfile(cfg, name)
+	if err != nil {
+		fail(err.Error(), "")
+		return nil, "", nil
+	}
+
+	r := doctor.CheckProfile(name, profile)
+	report(r)
+	if r.Status == doctor.Fail {
+		return profile, name, nil
+	}
+	u, _ := url.Parse(profile.APIURL)
+	return profile, name, u
+}
+
+func printDoctorResult(r doctor.Result) {
+	var mark string
+	switch r.Status {
+	case doctor.Pass:
+		mark = color.GreenString("✓")
+	case doctor.Warn:
+		mark = color.YellowString("!")
+	case doctor.Fail:
+		mark = color.RedString("✗")
+	default:
+		mark = color.HiBlackString("-")
+	}
+	fmt.Printf("%s %-16s %s\n", mark, r.Name, r.Detail)
+	if r.Hint != "" {
+		fmt.Printf("  %s %s\n", color.HiBlackString("→"), r.Hint)
+	}
+}
+
+func init() {
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 5*time.Second, "Timeout for each network check")
+	rootCmd.AddCommand(doctorCmd)
+}
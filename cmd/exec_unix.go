@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// execProgram replaces eg with the program at path, so it owns the terminal
+// and receives signals directly. args includes the program name.
+func execProgram(path string, args []string) error {
+	return syscall.Exec(path, args, os.Environ())
+}
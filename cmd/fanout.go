@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/fatih/color"
+)
+
+var allProfilesFlag bool
+
+// profileResult is the outcome of running a command against one profile.
+type profileResult struct {
+	Profile string
+	Value   any
+	Err     error
+}
+
+// isMultiProfile reports whether the command line asks for more than one
+// profile, via --profile a,b,c or --all-profiles.
+func isMultiProfile() bool {
+	return allProfilesFlag || strings.Contains(profileFlag, ",")
+}
+
+// targetProfiles returns the names of the profiles to run against, in order.
+func targetProfiles(cfg *config.Config) ([]string, error) {
+	if allProfilesFlag {
+		if len(cfg.Profiles) == 0 {
+			return nil, fmt.Errorf("no profiles configured. Run: eg profile add <name>")
+		}
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(profileFlag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, err := config.GetProfile(cfg, name); err != nil {
+			return nil, err
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		p := cfg.DefaultProfile
+		if _, err := config.GetProfile(cfg, p); err != nil {
+			return nil, err
+		}
+		names = append(names, p)
+	}
+	return names, nil
+}
+
+// runForProfiles runs fn against each target profile in parallel and returns
+// the results in profile order.
+func runForProfiles(fn func(name string, profile *config.Profile, client *api.Client) (any, error)) ([]profileResult, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	names, err := targetProfiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]profileResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			profile := cfg.Profiles[name]
+			client, err := newClient(name, &profile)
+			if err != nil {
+				results[i] = profileResult{Profile: name, Err: err}
+				return
+			}
+			v, err := fn(name, &profile, client)
+			results[i] = profileResult{Profile: name, Value: v, Err: err}
+		}(i, name)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// printProfileResults renders fan-out results. With a single profile the
+// output is exactly what the command prints without fan-out. With several,
+// table mode groups output under a header per profile and JSON mode keys the
+// results by profile name; a summary of failures follows and the returned
+// error carries exit status 2 on partial failure, 1 if every profile failed.
+func printProfileResults(results []profileResult, render func(v any)) error {
+	if !isMultiProfile() && len(results) == 1 {
+		r := results[0]
+		if r.Err != nil {
+			return r.Err
+		}
+		if output.Format == "json" {
+			output.PrintJSON(r.Value)
+		} else {
+			render(r.Value)
+		}
+		return nil
+	}
+
+	var failed []string
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.Profile)
+		}
+	}
+
+	if output.Format == "json" {
+		out := make(map[string]any, len(results))
+		for _, r := range results {
+			if r.Err != nil {
+				out[r.Profile] = map[string]any{"error": r.Err.Error()}
+			} else {
+				out[r.Profile] = map[string]any{"result": r.Value}
+			}
+		}
+		output.PrintJSON(out)
+	} else {
+		bold := color.New(color.Bold).SprintFunc()
+		for _, r := range results {
+			fmt.Println(bold("Profile: " + r.Profile))
+			if r.Err != nil {
+				output.Error("%v", r.Err)
+			} else {
+				render(r.Value)
+			}
+			fmt.Println()
+		}
+		if len(failed) > 0 {
+			output.Error("%d of %d profiles failed: %s", len(failed), len(results), strings.Join(failed, ", "))
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(failed) == len(results):
+		return &exitError{code: 1}
+	default:
+		return &exitError{code: 2}
+	}
+}
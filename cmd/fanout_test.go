@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+// setupProfiles writes a config with the given profiles to a temporary home
+// directory and targets all of them, as --all-profiles does.
+func setupProfiles(t *testing.T, profiles map[string]config.Profile) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	err := config.Update(func(cfg *config.Config) error {
+		cfg.DefaultProfile, cfg.Profiles = "a", profiles
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldAll, oldProfile, oldFormat := allProfilesFlag, profileFlag, output.Format
+	allProfilesFlag, profileFlag, output.Format = true, "", "json"
+	t.Cleanup(func() {
+		allProfilesFlag, profileFlag, output.Format = oldAll, oldProfile, oldFormat
+	})
+}
+
+// captureStdout returns what fn writes to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	defer func() { os.Stdout = old }()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestRunForProfiles(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+
+	good := config.Profile{APIURL: srv.URL, APIKey: srv.APIKey}
+	badKey := config.Profile{APIURL: srv.URL, APIKey: "wrong"}
+	badCA := config.Profile{APIURL: srv.URL, APIKey: srv.APIKey, CAFile: "/nonexistent/ca.pem"}
+
+	tests := []struct {
+		name     string
+		profiles map[string]config.Profile
+		wantCode int
+		wantErrs []string // profiles reported with an error
+	}{
+		{"all succeed", map[string]config.Profile{"a": good, "b": good}, 0, nil},
+		{"some fail", map[string]config.Profile{"a": good, "b": badKey}, 2, []string{"b"}},
+		{"all fail", map[string]config.Profile{"a": badKey, "b": badKey}, 1, []string{"a", "b"}},
+		{"client cannot be built", map[string]config.Profile{"a": good, "b": badCA}, 2, []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupProfiles(t, tt.profiles)
+
+			results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+				return client.GetMe()
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 2 || results[0].Profile != "a" || results[1].Profile != "b" {
+				t.Fatalf("results = %+v, want profiles a and b in order", results)
+			}
+
+			var printErr error
+			out := captureStdout(t, func() {
+				printErr = printProfileResults(results, func(v any) {})
+			})
+			if code := ExitCode(printErr); code != tt.wantCode {
+				t.Errorf("exit code = %d (%v), want %d", code, printErr, tt.wantCode)
+			}
+
+			var got map[string]struct {
+				Result *api.UserInfo `json:"result"`
+				Error  string        `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			var errs []string
+			for _, name := range []string{"a", "b"} {
+				r, ok := got[name]
+				switch {
+				case !ok:
+					t.Errorf("profile %s missing from output", name)
+				case r.Error != "":
+					errs = append(errs, name)
+				case r.Result == nil || r.Result.Email != srv.User.Email:
+					t.Errorf("profile %s: result = %+v", name, r.Result)
+				}
+			}
+			if strings.Join(errs, ",") != strings.Join(tt.wantErrs, ",") {
+				t.Errorf("profiles with errors = %v, want %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/entryguard-io/cli/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySince  string
+	historyFormat string
+	historyLimit  int
+	reportSince   string
+	reportTop     int
+)
+
+var sessionHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Export past sessions",
+	Long: `Page through past sessions, including when and why they ended.
+
+--since accepts a look-back duration (30d, 12h), a date (2024-05-01) or an
+RFC 3339 timestamp. --format csv writes a spreadsheet-friendly export.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := historyFormat
+		if format == "" {
+			format = output.Format
+		}
+		if format != "table" && format != "json" && format != "csv" {
+			return fmt.Errorf("unsupported format %q (use table, json or csv)", format)
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		since, err := duration.ParseSince(historySince, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+
+		sessions, err := client.ListAllSessionHistory(api.SessionHistoryQuery{Since: since}, historyLimit)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "json":
+			output.PrintJSON(sessions)
+		case "csv":
+			return writeSessionsCSV(sessions)
+		default:
+			var rows [][]string
+			for _, s := range sessions {
+				_, span := report.Span(s, time.Now())
+				rows = append(rows, []string{
+					output.ShortID(s.ID),
+					output.StatusColor(s.Status),
+					sessionIPs(&s),
+					output.FormatTime(s.StartedAt),
+					output.FormatTime(s.EndedAt),
+					valueOrDash(s.EndedReason),
+					output.FormatSpan(span),
+					truncate(sessionReasonText(&s), 40),
+				})
+			}
+			output.PrintTable([]string{"ID", "STATUS", "IP", "STARTED", "ENDED", "END REASON", "LENGTH", "REASON"}, rows)
+		}
+		return nil
+	},
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarise whitelisted time per user, IP and resource",
+	Long: `Summarise whitelisted time per user, IP and resource.
+
+For organization admins the report covers every user's sessions. Other users
+get a report of their own sessions, without the per-user table.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		since, err := duration.ParseSince(reportSince, now)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+
+		user, err := client.GetMe()
+		if err != nil {
+			return err
+		}
+		q := api.SessionHistoryQuery{Since: since}
+		var sessions []api.Session
+		if user.IsOrgAdmin {
+			sessions, err = client.ListAllOrgSessionHistory(q, 0)
+		} else {
+			sessions, err = client.ListAllSessionHistory(q, 0)
+		}
+		if err != nil {
+			return err
+		}
+
+		r := report.Build(sessions, since, now)
+		if !user.IsOrgAdmin {
+			// Only the caller's own sessions; a table of one user says nothing.
+			r.Users = nil
+		}
+		r.Users = report.Top(r.Users, reportTop)
+		r.IPs = report.Top(r.IPs, reportTop)
+		r.Resources = report.Top(r.Resources, reportTop)
+
+		if output.Format == "json" {
+			output.PrintJSON(r)
+			return nil
+		}
+
+		if user.IsOrgAdmin {
+			fmt.Printf("%d sessions in %s since %s\n\n", r.Sessions, user.OrganizationName, since.Local().Format("2006-01-02 15:04"))
+			printReportSection("Top users", "USER", r.Users)
+		} else {
+			fmt.Printf("%d of your sessions since %s\n\n", r.Sessions, since.Local().Format("2006-01-02 15:04"))
+		}
+		printReportSection("Top IPs", "IP", r.IPs)
+		printReportSection("Top resources", "RESOURCE", r.Resources)
+		return nil
+	},
+}
+
+func printReportSection(title, keyHeader string, entries []report.Entry) {
+	fmt.Println(title)
+	var rows [][]string
+	for _, e := range entries {
+		rows = append(rows, []string{
+			e.Key,
+			strconv.Itoa(e.Sessions),
+			output.FormatSpan(e.Total),
+			e.LastSeen.Local().Format("2006-01-02 15:04"),
+		})
+	}
+	output.PrintTable([]string{keyHeader, "SESSIONS", "TOTAL", "LAST STARTED"}, rows)
+	fmt.Println()
+}
+
+func writeSessionsCSV(sessions []api.Session) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{
+		"id", "status", "user_name", "user_email", "ipv4", "ipv6",
+		"started_at", "expires_at", "ended_at", "ended_reason",
+		"reason", "ticket", "resources", "duration_seconds",
+	})
+
+	now := time.Now()
+	for _, s := range sessions {
+		_, span := report.Span(s, now)
+		var names []string
+		seen := make(map[string]bool)
+		for _, r := range s.ResourceIps {
+			if !seen[r.ResourceName] {
+				seen[r.ResourceName] = true
+				names = append(names, r.ResourceName)
+			}
+		}
+		w.Write([]string{
+			s.ID, s.Status, s.UserName, s.UserEmail, s.Ipv4Address, s.Ipv6Address,
+			s.StartedAt, s.ExpiresAt, s.EndedAt, s.EndedReason,
+			s.Reason, s.TicketRef, strings.Join(names, ";"),
+			strconv.FormatInt(int64(span/time.Second), 10),
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func init() {
+	sessionHistoryCmd.Flags().StringVar(&historySince, "since", "30d", "How far back to go (e.g. 30d, 12h, 2024-05-01)")
+	sessionHistoryCmd.Flags().StringVar(&historyFormat, "format", "", "Output format: table, json or csv (defaults to --output)")
+	sessionHistoryCmd.Flags().IntVar(&historyLimit, "limit", 0, "Maximum number of sessions to fetch (0 = all)")
+	sessionCmd.AddCommand(sessionHistoryCmd)
+
+	reportCmd.Flags().StringVar(&reportSince, "since", "30d", "How far back to go (e.g. 30d, 12h, 2024-05-01)")
+	reportCmd.Flags().IntVar(&reportTop, "top", 10, "Rows per table (0 = all)")
+	rootCmd.AddCommand(reportCmd)
+}
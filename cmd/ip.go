@@ -1,9 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/entryguard-io/cli/internal/api"
 	"github.com/entryguard-io/cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -12,7 +12,7 @@ var ipCmd = &cobra.Command{
 	Use:   "ip",
 	Short: "Detect your public IP addresses",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ipv4, ipv6 := api.DetectIPs()
+		ipv4, ipv6 := detectIPs(context.Background())
 
 		if output.Format == "json" {
 			result := map[string]string{}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/auth"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginAPIURL    string
+	loginNoBrowser bool
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in with your browser",
+	Long: `Sign in through your browser instead of pasting an API key.
+
+eg login shows a short code and opens the EntryGuard sign-in page; once you
+approve the code there, a short-lived access token and a refresh token are
+stored in the profile (--profile, else the default profile, else "default").
+The access token is refreshed automatically. Any API key previously stored in
+the profile is replaced.`,
+	Example: `  eg login
+  eg login --profile work --api-url https://eg.example.com/api/v1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isMultiProfile() {
+			return fmt.Errorf("eg login signs in to one profile at a time")
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := profileFlag
+		if name == "" {
+			name = cfg.DefaultProfile
+		}
+		if name == "" {
+			name = "default"
+		}
+		profile := cfg.Profiles[name]
+		if loginAPIURL != "" {
+			profile.APIURL = loginAPIURL
+		}
+		if profile.APIURL == "" {
+			profile.APIURL = defaultAPIURL
+		}
+
+		tr, err := transportConfig(&profile).Transport()
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		httpClient := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+		authCfg := loginConfig(profile.APIURL, httpClient)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		dc, err := auth.RequestDeviceCode(ctx, authCfg)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("To sign in, open:\n\n  %s\n\nand enter the code: %s\n\n", dc.VerificationURI, dc.UserCode)
+		if !loginNoBrowser {
+			target := dc.VerificationURIComplete
+			if target == "" {
+				target = dc.VerificationURI
+			}
+			if openBrowser(target) == nil {
+				output.Info("Opened your browser")
+			}
+		}
+		output.Info("Waiting for approval...")
+
+		token, err := auth.PollToken(ctx, authCfg, dc)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("login cancelled")
+			}
+			return err
+		}
+
+		client := api.NewClient(profile.APIURL, "")
+		client.HTTPClient = httpClient
+		src := auth.NewSource(authCfg, *token)
+		client.Tokens = src
+		user, err := client.GetMe()
+		if err != nil {
+			return fmt.Errorf("login succeeded but the API rejected the token: %w", err)
+		}
+
+		// GetMe may already have refreshed a very short-lived token.
+		latest := src.Token()
+		profile.APIKey = ""
+		setProfileToken(&profile, &latest)
+		// The config is re-read under the lock: the device flow can take
+		// minutes, and other eg processes may have refreshed tokens since.
+		onlyProfile := false
+		err = config.Update(func(cfg *config.Config) error {
+			config.AddProfile(cfg, name, profile)
+			onlyProfile = cfg.DefaultProfile == name && len(cfg.Profiles) == 1
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		output.Success("Logged in as %s (org: %s); profile %q saved", user.Email, user.OrganizationName, name)
+		if onlyProfile {
+			output.Info("Set as default profile")
+		}
+		return nil
+	},
+}
+
+func loginConfig(apiURL string, httpClient *http.Client) *auth.Config {
+	return &auth.Config{
+		BaseURL:    apiURL,
+		HTTPClient: httpClient,
+		Scope:      "offline_access",
+	}
+}
+
+func profileToken(p *config.Profile) auth.Token {
+	token := auth.Token{AccessToken: p.AccessToken, RefreshToken: p.RefreshToken}
+	if t, err := time.Parse(time.RFC3339, p.TokenExpiry); err == nil {
+		token.Expiry = t
+	}
+	return token
+}
+
+func setProfileToken(p *config.Profile, token *auth.Token) {
+	p.AccessToken = token.AccessToken
+	p.RefreshToken = token.RefreshToken
+	p.TokenExpiry = ""
+	if !token.Expiry.IsZero() {
+		p.TokenExpiry = token.Expiry.UTC().Format(time.RFC3339)
+	}
+}
+
+// saveLoginToken stores a refreshed token in the named profile. The config
+// is updated under a lock so refreshes of other profiles, in this process or
+// another eg process, are not lost.
+func saveLoginToken(name string, token *auth.Token) error {
+	return config.Update(func(cfg *config.Config) error {
+		p, ok := cfg.Profiles[name]
+		if !ok {
+			return fmt.Errorf("profile %q no longer exists", name)
+		}
+		setProfileToken(&p, token)
+		cfg.Profiles[name] = p
+		return nil
+	})
+}
+
+// openBrowser opens url in the user's default browser.
+func openBrowser(url string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+	return c.Start()
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginAPIURL, "api-url", "", "API URL for a new profile (default "+defaultAPIURL+")")
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Only print the sign-in URL and code")
+	rootCmd.AddCommand(loginCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/entryguard-io/cli/internal/config"
+)
+
+// TestConcurrentRefreshKeepsBothTokens refreshes two login profiles at once,
+// as fan-out does, against a server that rotates refresh tokens. Each
+// profile must end up with its own rotated token in config.toml.
+func TestConcurrentRefreshKeepsBothTokens(t *testing.T) {
+	var mu sync.Mutex
+	issued := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/oauth/token" || r.ParseForm() != nil {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		issued++
+		n := issued
+		mu.Unlock()
+		old := r.Form.Get("refresh_token")
+		prefix := old[:strings.LastIndex(old, "-")]
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("at-%d", n),
+			"refresh_token": fmt.Sprintf("%s-%d", prefix, n),
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	defer srv.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	names := []string{"alpha", "beta"}
+	for round := 0; round < 20; round++ {
+		var cfg *config.Config
+		err := config.Update(func(c *config.Config) error {
+			for _, name := range names {
+				config.AddProfile(c, name, config.Profile{
+					APIURL:       srv.URL + "/api/v1",
+					AccessToken:  "expired",
+					RefreshToken: name + "-0",
+					TokenExpiry:  "2000-01-01T00:00:00Z",
+				})
+			}
+			cfg = c
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for _, name := range names {
+			profile := cfg.Profiles[name]
+			client, err := newClient(name, &profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.Tokens.AccessToken(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		saved, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			if rt := saved.Profiles[name].RefreshToken; rt == name+"-0" {
+				t.Fatalf("round %d: profile %s kept its stale refresh token", round, name)
+			}
+		}
+	}
+}
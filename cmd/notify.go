@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/lifecycle"
+	"github.com/entryguard-io/cli/internal/notify"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyBefore   []time.Duration
+	notifyInterval time.Duration
+	notifyTerminal bool
+	notifyDetach   bool
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Show desktop notifications before sessions expire",
+	Long: `Watch your active sessions and raise a desktop notification when one is
+about to expire, at each lead time given with --before, and when one has
+expired.
+
+On Linux and the BSDs notifications go over D-Bus to the desktop's
+notification service (through gdbus, or notify-send); on macOS through
+osascript. Where neither works, or with --terminal, eg rings the terminal
+bell and prints the notification on stderr instead.
+
+--detach runs the watcher in the background, detached from the terminal,
+and logs its output to ~/.entryguard/notify.log. It watches the profiles
+selected with --profile or --all-profiles.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(notifyBefore) == 0 {
+			return fmt.Errorf("--before needs at least one lead time")
+		}
+		for _, d := range notifyBefore {
+			if d <= 0 {
+				return fmt.Errorf("--before lead times must be positive")
+			}
+		}
+		if notifyInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if notifyDetach {
+			if notifyTerminal {
+				return fmt.Errorf("--terminal cannot be used with --detach: a detached watcher has no terminal")
+			}
+			if err := notify.Available(); err != nil {
+				return fmt.Errorf("cannot notify in the background: %w. Run eg notify in a terminal instead", err)
+			}
+			return detachNotify()
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		names, err := targetProfiles(cfg)
+		if err != nil {
+			return err
+		}
+
+		// Longest lead time first, so a session inside several windows at
+		// once is announced for the shortest.
+		leads := slices.Clone(notifyBefore)
+		slices.SortFunc(leads, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+
+		var watchers []*notifyWatcher
+		for _, name := range names {
+			profile := cfg.Profiles[name]
+			client, err := newClient(name, &profile)
+			if err != nil {
+				return err
+			}
+			w := &notifyWatcher{name: name, client: client}
+			for _, d := range leads {
+				w.trackers = append(w.trackers, lifecycle.NewTracker(d))
+			}
+			watchers = append(watchers, w)
+		}
+
+		output.Info("Notifying %s before sessions expire (Ctrl-C to stop)", formatLeads(leads))
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		ticker := time.NewTicker(notifyInterval)
+		defer ticker.Stop()
+
+		for {
+			for _, w := range watchers {
+				w.poll()
+			}
+			select {
+			case <-ticker.C:
+			case <-sigCh:
+				return nil
+			}
+		}
+	},
+}
+
+// notifyWatcher follows one profile's sessions with a tracker per lead time.
+type notifyWatcher struct {
+	name     string
+	client   *api.Client
+	trackers []*lifecycle.Tracker
+}
+
+func (w *notifyWatcher) poll() {
+	sessions, err := listSessionsFor(w.name, w.client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: poll failed: %v\n", w.name, err)
+		return
+	}
+
+	now := time.Now()
+	expiring := make(map[string]api.Session)
+	var order []string
+	for i, tr := range w.trackers {
+		for _, c := range tr.Update(sessions, now) {
+			switch {
+			case c.Event == lifecycle.Expiring:
+				if _, ok := expiring[c.Session.ID]; !ok {
+					order = append(order, c.Session.ID)
+				}
+				expiring[c.Session.ID] = c.Session
+			case c.Event == lifecycle.Ended && i == 0:
+				if s := finalSession(w.name, w.client, c.Session); s.Status == "EXPIRED" {
+					deliver(expiredNotification(w.name, &s))
+				}
+			}
+		}
+	}
+	for _, id := range order {
+		s := expiring[id]
+		deliver(expiringNotification(w.name, &s, now))
+	}
+}
+
+func expiringNotification(profile string, s *api.Session, now time.Time) notify.Notification {
+	left, _ := s.Remaining(now)
+	return notify.Notification{
+		Title: "EntryGuard session expiring",
+		Body: fmt.Sprintf("Session %s%s expires in %s (%s).\nExtend with: eg%s session extend %s --for 1h",
+			output.ShortID(s.ID), profileSuffix(profile), output.FormatSpan(left), output.FormatTime(s.ExpiresAt),
+			profileArg(profile), output.ShortID(s.ID)),
+		Urgent: left <= 5*time.Minute,
+	}
+}
+
+func expiredNotification(profile string, s *api.Session) notify.Notification {
+	return notify.Notification{
+		Title: "EntryGuard session expired",
+		Body: fmt.Sprintf("Session %s%s has expired and its IPs are no longer whitelisted.\nStart a new one with: eg%s session start",
+			output.ShortID(s.ID), profileSuffix(profile), profileArg(profile)),
+		Urgent: true,
+	}
+}
+
+// profileSuffix and profileArg name the profile in notifications when more
+// than one is watched.
+func profileSuffix(profile string) string {
+	if !isMultiProfile() {
+		return ""
+	}
+	return fmt.Sprintf(" (profile %s)", profile)
+}
+
+func profileArg(profile string) string {
+	if !isMultiProfile() {
+		return ""
+	}
+	return " --profile " + profile
+}
+
+var notifyFallbackWarned bool
+
+// deliver shows n on the desktop, falling back to the terminal.
+func deliver(n notify.Notification) {
+	fmt.Printf("%s  %s\n", time.Now().Format("15:04:05"), n.Title)
+	if !notifyTerminal {
+		err := notify.Send(n)
+		if err == nil {
+			return
+		}
+		if !notifyFallbackWarned {
+			notifyFallbackWarned = true
+			output.Error("Desktop notification failed (%v); using the terminal bell instead", err)
+		}
+	}
+	notify.Terminal(os.Stderr, n)
+}
+
+func formatLeads(leads []time.Duration) string {
+	parts := make([]string, len(leads))
+	for i, d := range leads {
+		parts[i] = output.FormatSpan(d)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// detachNotify restarts eg notify with the same arguments in the background,
+// logging its output to ~/.entryguard/notify.log so failed notifications
+// are not lost.
+func detachNotify() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	logPath := filepath.Join(dir, "notify.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer logFile.Close()
+	var args []string
+	for _, a := range os.Args[1:] {
+		if a != "--detach" && !strings.HasPrefix(a, "--detach=") {
+			args = append(args, a)
+		}
+	}
+	c := exec.Command(exe, args...)
+	c.Stdout, c.Stderr = logFile, logFile
+	detach(c)
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to start background watcher: %w", err)
+	}
+	output.Success("Watching sessions in the background (pid %d, log: %s)", c.Process.Pid, logPath)
+	return c.Process.Release()
+}
+
+func init() {
+	notifyCmd.Flags().DurationSliceVar(&notifyBefore, "before", []time.Duration{10 * time.Minute, 2 * time.Minute}, "Lead times before expiry to notify at, e.g. 15m,5m,1m")
+	notifyCmd.Flags().DurationVar(&notifyInterval, "interval", 30*time.Second, "How often to poll")
+	notifyCmd.Flags().BoolVar(&notifyTerminal, "terminal", false, "Only ring the terminal bell and print to stderr")
+	notifyCmd.Flags().BoolVar(&notifyDetach, "detach", false, "Run in the background")
+	rootCmd.AddCommand(notifyCmd)
+}
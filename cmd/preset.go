@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presetProject       bool
+	presetDuration      string
+	presetResources     []string
+	presetExclude       []string
+	presetV4Prefix      int
+	presetV6Prefix      int
+	presetReason        string
+	presetRequireReason bool
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage session presets",
+	Long: `Manage named presets for "eg session start --preset <name>".
+
+Presets live in ~/.entryguard/config.toml, or with --project in the nearest
+.entryguard.toml (searched from the current directory upwards) so they can be
+committed and shared. User presets override project presets of the same name.
+
+Reason templates may use {{.Reason}}, {{.Ticket}}, {{.User}}, {{.Date}} and
+{{.Preset}}, e.g. "db maintenance: {{.Reason}}".`,
+}
+
+var presetAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a preset",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if presetDuration != "" {
+			if _, err := duration.Parse(presetDuration); err != nil {
+				return fmt.Errorf("invalid --duration: %w", err)
+			}
+		}
+		if err := checkPrefixLengths(presetV4Prefix, presetV6Prefix); err != nil {
+			return err
+		}
+		p := config.Preset{
+			Duration:      presetDuration,
+			Resources:     presetResources,
+			Exclude:       presetExclude,
+			Ipv4Prefix:    presetV4Prefix,
+			Ipv6Prefix:    presetV6Prefix,
+			Reason:        presetReason,
+			RequireReason: presetRequireReason,
+		}
+		if _, err := p.RenderReason(config.ReasonData{Reason: "x", Preset: name}); err != nil {
+			return err
+		}
+
+		if presetProject {
+			path, pc, err := loadProjectConfig(true)
+			if err != nil {
+				return err
+			}
+			pc.Presets[name] = p
+			if err := config.SaveProject(path, pc); err != nil {
+				return fmt.Errorf("failed to save project config: %w", err)
+			}
+			output.Success("Preset %q saved to %s", name, path)
+			if cfg, err := loadConfig(); err == nil {
+				if _, ok := cfg.Presets[name]; ok {
+					output.Info("Your own preset %q takes precedence over it", name)
+				}
+			}
+			return nil
+		}
+
+		err := config.Update(func(cfg *config.Config) error {
+			cfg.Presets[name] = p
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		output.Success("Preset %q saved", name)
+		return nil
+	},
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List presets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := loadPresets()
+		if err != nil {
+			return err
+		}
+
+		if output.Format == "json" {
+			type presetJSON struct {
+				Name          string   `json:"name"`
+				Source        string   `json:"source"`
+				Duration      string   `json:"duration,omitempty"`
+				Resources     []string `json:"resources,omitempty"`
+				Exclude       []string `json:"exclude,omitempty"`
+				Ipv4Prefix    int      `json:"ipv4Prefix,omitempty"`
+				Ipv6Prefix    int      `json:"ipv6Prefix,omitempty"`
+				Reason        string   `json:"reason,omitempty"`
+				RequireReason bool     `json:"requireReason"`
+			}
+			out := make([]presetJSON, 0, len(entries))
+			for _, e := range entries {
+				out = append(out, presetJSON{
+					Name:          e.Name,
+					Source:        e.Source,
+					Duration:      e.Preset.Duration,
+					Resources:     e.Preset.Resources,
+					Exclude:       e.Preset.Exclude,
+					Ipv4Prefix:    e.Preset.Ipv4Prefix,
+					Ipv6Prefix:    e.Preset.Ipv6Prefix,
+					Reason:        e.Preset.Reason,
+					RequireReason: e.Preset.RequireReason,
+				})
+			}
+			output.PrintJSON(out)
+			return nil
+		}
+
+		var rows [][]string
+		for _, e := range entries {
+			p := e.Preset
+			resources := strings.Join(p.Resources, ", ")
+			if len(p.Exclude) > 0 {
+				if resources != "" {
+					resources += " "
+				}
+				resources += "!" + strings.Join(p.Exclude, " !")
+			}
+			reason := p.Reason
+			if p.RequireReason {
+				reason += " (required)"
+			}
+			rows = append(rows, []string{
+				e.Name,
+				valueOrDash(p.Duration),
+				valueOrDash(resources),
+				valueOrDash(presetPrefixes(p)),
+				valueOrDash(strings.TrimSpace(reason)),
+				e.Source,
+			})
+		}
+		output.PrintTable([]string{"NAME", "DURATION", "RESOURCES", "PREFIX", "REASON", "SOURCE"}, rows)
+		return nil
+	},
+}
+
+var presetRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Short:             "Remove a preset",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completePresetNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if presetProject {
+			path, pc, err := loadProjectConfig(false)
+			if err != nil {
+				return err
+			}
+			if _, ok := pc.Presets[name]; !ok {
+				return fmt.Errorf("preset %q not found in %s", name, path)
+			}
+			delete(pc.Presets, name)
+			if err := config.SaveProject(path, pc); err != nil {
+				return fmt.Errorf("failed to save project config: %w", err)
+			}
+			output.Success("Preset %q removed from %s", name, path)
+			return nil
+		}
+
+		err := config.Update(func(cfg *config.Config) error {
+			if _, ok := cfg.Presets[name]; !ok {
+				return fmt.Errorf("preset %q not found in user config (use --project for project presets)", name)
+			}
+			delete(cfg.Presets, name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		output.Success("Preset %q removed", name)
+		return nil
+	},
+}
+
+// applyPreset fills in `session start` options from a preset. Flags given
+// explicitly on the command line take precedence over the preset.
+func applyPreset(cmd *cobra.Command, name string) (*config.Preset, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	cwd, _ := os.Getwd()
+	entry, err := config.GetPreset(cfg, cwd, name)
+	if err != nil {
+		return nil, err
+	}
+	p := entry.Preset
+	switch {
+	case entry.Shadows != "":
+		output.Info("Using your preset %q, not the one in %s", name, entry.Shadows)
+	case entry.Source != "user":
+		output.Info("Using preset %q from %s", name, entry.Source)
+	}
+
+	flags := cmd.Flags()
+	if p.Duration != "" && !flags.Changed("duration") && !flags.Changed("for") && !flags.Changed("until") {
+		sessionDuration = p.Duration
+	}
+	if len(p.Resources) > 0 && !flags.Changed("resource") {
+		sessionInclude = p.Resources
+	}
+	if len(p.Exclude) > 0 && !flags.Changed("exclude") {
+		sessionExclude = p.Exclude
+	}
+	if p.Ipv4Prefix > 0 && !flags.Changed("ipv4-prefix") {
+		sessionV4Prefix = p.Ipv4Prefix
+	}
+	if p.Ipv6Prefix > 0 && !flags.Changed("ipv6-prefix") {
+		sessionV6Prefix = p.Ipv6Prefix
+	}
+	return &p, nil
+}
+
+func loadPresets() ([]config.PresetEntry, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	cwd, _ := os.Getwd()
+	return config.Presets(cfg, cwd)
+}
+
+// loadProjectConfig finds the nearest .entryguard.toml. With create, a new
+// one is started in the current directory if none exists.
+func loadProjectConfig(create bool) (string, *config.ProjectConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	path := config.FindProjectFile(cwd)
+	if path == "" {
+		if !create {
+			return "", nil, fmt.Errorf("no %s found in %s or its parents", config.ProjectFile, cwd)
+		}
+		return config.ProjectFile, &config.ProjectConfig{Presets: make(map[string]config.Preset)}, nil
+	}
+	pc, err := config.LoadProject(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, pc, nil
+}
+
+func presetPrefixes(p config.Preset) string {
+	var parts []string
+	if p.Ipv4Prefix > 0 {
+		parts = append(parts, "v4 /"+strconv.Itoa(p.Ipv4Prefix))
+	}
+	if p.Ipv6Prefix > 0 {
+		parts = append(parts, "v6 /"+strconv.Itoa(p.Ipv6Prefix))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// completePresetNames suggests preset names from user and project config.
+func completePresetNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	entries, err := loadPresets()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name, toComplete) {
+			completions = append(completions, e.Name+"\t"+e.Source)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	presetCmd.PersistentFlags().BoolVar(&presetProject, "project", false, "Use the project's .entryguard.toml instead of the user config")
+
+	presetAddCmd.Flags().StringVar(&presetDuration, "duration", "", "Session length, e.g. 2h, 45m")
+	presetAddCmd.Flags().StringArrayVar(&presetResources, "resource", nil, "Resource name, ID or glob such as db-* (repeatable)")
+	presetAddCmd.Flags().StringArrayVar(&presetExclude, "exclude", nil, "Resource to skip (repeatable)")
+	presetAddCmd.Flags().IntVar(&presetV4Prefix, "ipv4-prefix", 0, "IPv4 prefix length to whitelist")
+	presetAddCmd.Flags().IntVar(&presetV6Prefix, "ipv6-prefix", 0, "IPv6 prefix length to whitelist")
+	presetAddCmd.Flags().StringVar(&presetReason, "reason", "", "Reason template, e.g. \"db maintenance: {{.Reason}}\"")
+	presetAddCmd.Flags().BoolVar(&presetRequireReason, "require-reason", false, "Require --reason when using this preset")
+	presetAddCmd.RegisterFlagCompletionFunc("resource", completeResourceNames)
+	presetAddCmd.RegisterFlagCompletionFunc("exclude", completeResourceNames)
+
+	presetCmd.AddCommand(presetAddCmd)
+	presetCmd.AddCommand(presetListCmd)
+	presetCmd.AddCommand(presetRemoveCmd)
+	rootCmd.AddCommand(presetCmd)
+}
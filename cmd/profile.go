@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/entryguard-io/cli/internal/api"
@@ -13,6 +14,15 @@ import (
 	"golang.org/x/term"
 )
 
+var (
+	profileCAFile     string
+	profileClientCert string
+	profileClientKey  string
+	profileInsecure   bool
+	profileProxyURL   string
+	profileTunnelHost string
+)
+
 var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Manage configuration profiles",
@@ -48,37 +58,176 @@ var profileAddCmd = &cobra.Command{
 			return fmt.Errorf("API key cannot be empty")
 		}
 
-		fmt.Print("API URL [https://app.entryguard.io/api/v1]: ")
+		fmt.Printf("API URL [%s]: ", defaultAPIURL)
 		apiURL, _ := reader.ReadString('\n')
 		apiURL = strings.TrimSpace(apiURL)
 		if apiURL == "" {
-			apiURL = "https://app.entryguard.io/api/v1"
+			apiURL = defaultAPIURL
+		}
+
+		if err := checkConnectionFlags(); err != nil {
+			return err
+		}
+
+		profile := config.Profile{
+			APIKey:             apiKey,
+			APIURL:             apiURL,
+			CAFile:             profileCAFile,
+			ClientCert:         profileClientCert,
+			ClientKey:          profileClientKey,
+			InsecureSkipVerify: profileInsecure,
+			ProxyURL:           profileProxyURL,
+			TunnelHost:         profileTunnelHost,
 		}
 
 		output.Info("Validating API key...")
-		client := api.NewClient(apiURL, apiKey)
+		client, err := newClient(name, &profile)
+		if err != nil {
+			return err
+		}
 		user, err := client.GetMe()
 		if err != nil {
 			return fmt.Errorf("API key validation failed: %w", err)
 		}
 
-		config.AddProfile(cfg, name, config.Profile{
-			APIKey: apiKey,
-			APIURL: apiURL,
+		isDefault := false
+		err = config.Update(func(cfg *config.Config) error {
+			if _, exists := cfg.Profiles[name]; exists {
+				return fmt.Errorf("profile %q already exists. Remove it first with: eg profile remove %s", name, name)
+			}
+			config.AddProfile(cfg, name, profile)
+			isDefault = cfg.DefaultProfile == name
+			return nil
 		})
-
-		if err := config.Save(cfg); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
+		if err != nil {
+			return err
 		}
 
 		output.Success("Profile %q added (org: %s, user: %s)", name, user.OrganizationName, user.Email)
-		if cfg.DefaultProfile == name {
+		if isDefault {
 			output.Info("Set as default profile")
 		}
 		return nil
 	},
 }
 
+var profileSetCmd = &cobra.Command{
+	Use:   "set [name]",
+	Short: "Change a profile's connection settings",
+	Long: `Change the connection settings of an existing profile without re-entering
+its API key. Only the flags given are changed; pass an empty value (e.g.
+--proxy-url "") or --insecure-skip-verify=false to clear a setting.
+
+The settings are stored in the profile's section of the config file as
+ca_file, client_cert, client_key, insecure_skip_verify, proxy_url and
+tunnel_host.`,
+	Example: `  eg profile set prod --ca-file ~/certs/corp-ca.pem
+  eg profile set --proxy-url http://proxy.internal:3128
+  eg profile set prod --tunnel-host ""`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArgOnly(completeProfileNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		name := resolveProfileName()
+		if len(args) == 1 {
+			name = args[0]
+		} else if isMultiProfile() {
+			return fmt.Errorf("set works on one profile at a time: eg profile set <name>")
+		}
+		profile, err := config.GetProfile(cfg, name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = cfg.DefaultProfile
+		}
+		if err := checkConnectionFlags(); err != nil {
+			return err
+		}
+
+		// apply sets the changed flags on p. It runs once on a copy to
+		// validate the result and again on the profile as re-read under the
+		// config lock, so fields changed meanwhile (refreshed tokens) are kept.
+		flags := cmd.Flags()
+		apply := func(p *config.Profile) (changed bool) {
+			for _, f := range []struct {
+				flag  string
+				field *string
+				value string
+			}{
+				{"ca-file", &p.CAFile, profileCAFile},
+				{"client-cert", &p.ClientCert, profileClientCert},
+				{"client-key", &p.ClientKey, profileClientKey},
+				{"proxy-url", &p.ProxyURL, profileProxyURL},
+				{"tunnel-host", &p.TunnelHost, profileTunnelHost},
+			} {
+				if flags.Changed(f.flag) {
+					*f.field = f.value
+					changed = true
+				}
+			}
+			if flags.Changed("insecure-skip-verify") {
+				p.InsecureSkipVerify = profileInsecure
+				changed = true
+			}
+			return changed
+		}
+
+		updated := *profile
+		if !apply(&updated) {
+			return fmt.Errorf("nothing to change; see eg profile set --help")
+		}
+		if (updated.ClientCert == "") != (updated.ClientKey == "") {
+			return fmt.Errorf("client_cert and client_key must be set together")
+		}
+
+		// Catch unreadable certificates and bad proxy URLs before saving.
+		if _, err := newClient(name, &updated); err != nil {
+			return err
+		}
+
+		err = config.Update(func(cfg *config.Config) error {
+			p, ok := cfg.Profiles[name]
+			if !ok {
+				return fmt.Errorf("profile %q no longer exists", name)
+			}
+			apply(&p)
+			cfg.Profiles[name] = p
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		output.Success("Profile %q updated", name)
+		return nil
+	},
+}
+
+// checkConnectionFlags validates the connection flags shared by profile add
+// and profile set, and makes file paths absolute; eg runs from any
+// directory.
+func checkConnectionFlags() error {
+	for _, p := range []*string{&profileCAFile, &profileClientCert, &profileClientKey} {
+		if *p != "" {
+			abs, err := filepath.Abs(*p)
+			if err != nil {
+				return err
+			}
+			*p = abs
+		}
+	}
+
+	if profileTunnelHost != "" {
+		if err := api.ValidateTunnelHost(profileTunnelHost); err != nil {
+			return fmt.Errorf("invalid --tunnel-host: %w", err)
+		}
+	}
+	return nil
+}
+
 var profileListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all profiles",
@@ -125,60 +274,196 @@ var profileListCmd = &cobra.Command{
 }
 
 var profileUseCmd = &cobra.Command{
-	Use:   "use <name>",
-	Short: "Set the default profile",
-	Args:  cobra.ExactArgs(1),
+	Use:               "use <name>",
+	Short:             "Set the default profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeProfileNames),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
-		cfg, err := loadConfig()
+		err := config.Update(func(cfg *config.Config) error {
+			if _, ok := cfg.Profiles[name]; !ok {
+				return fmt.Errorf("profile %q not found", name)
+			}
+			cfg.DefaultProfile = name
+			return nil
+		})
 		if err != nil {
 			return err
 		}
 
-		if _, ok := cfg.Profiles[name]; !ok {
-			return fmt.Errorf("profile %q not found", name)
-		}
-
-		cfg.DefaultProfile = name
-		if err := config.Save(cfg); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
-		}
-
 		output.Success("Default profile set to %q", name)
 		return nil
 	},
 }
 
 var profileRemoveCmd = &cobra.Command{
-	Use:   "remove <name>",
-	Short: "Remove a profile",
-	Args:  cobra.ExactArgs(1),
+	Use:               "remove <name>",
+	Short:             "Remove a profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeProfileNames),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
+		err := config.Update(func(cfg *config.Config) error {
+			return config.RemoveProfile(cfg, name)
+		})
+		if err != nil {
+			return err
+		}
+
+		output.Success("Profile %q removed", name)
+		return nil
+	},
+}
+
+var (
+	rotateExpires string
+	rotateKeepOld bool
+	rotateScopes  []string
+	rotateYes     bool
+)
+
+var profileRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key [name]",
+	Short: "Replace a profile's API key with a freshly created one",
+	Long: `Create a new API key with the same name and scopes as the profile's current
+key, check that it works, save it to the profile and revoke the old key.
+
+If the current key is not in your key list its scopes are unknown, and a key
+created without scopes gets the server's defaults, which may grant more. In
+that case pass the scopes to keep with --scope, or confirm (--yes) that the
+defaults are acceptable.
+
+If anything fails before the profile is saved, the new key is revoked and the
+profile is left untouched.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: firstArgOnly(completeProfileNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := loadConfig()
 		if err != nil {
 			return err
 		}
+		name := resolveProfileName()
+		if len(args) == 1 {
+			name = args[0]
+		} else if isMultiProfile() {
+			return fmt.Errorf("rotate-key works on one profile at a time: eg profile rotate-key <name>")
+		}
+		profile, err := config.GetProfile(cfg, name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = cfg.DefaultProfile
+		}
+		if profile.UsesLogin() {
+			return fmt.Errorf("profile %q signs in with eg login and has no API key to rotate", name)
+		}
+		expiresAt, err := apikeyExpiry(rotateExpires)
+		if err != nil {
+			return err
+		}
 
-		if err := config.RemoveProfile(cfg, name); err != nil {
+		oldClient, err := newClient(name, profile)
+		if err != nil {
 			return err
 		}
+		keys, err := oldClient.ListApiKeys()
+		if err != nil {
+			return fmt.Errorf("failed to list API keys: %w", err)
+		}
+		old := api.FindApiKey(keys, profile.APIKey)
 
-		if err := config.Save(cfg); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
+		req := &api.CreateApiKeyRequest{
+			Name:      "eg-cli " + name,
+			ExpiresAt: expiresAt,
+		}
+		if old != nil {
+			req.Name = old.Name
+			req.Scopes = old.Scopes
+		}
+		if len(rotateScopes) > 0 {
+			req.Scopes = rotateScopes
+		} else if old == nil && !rotateYes {
+			ok, err := confirm("The current key is not in your key list, so its scopes are unknown. Create the new key with the server's default scopes?")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("aborted; pass --scope to choose the new key's scopes")
+			}
+		}
+		created, err := oldClient.CreateApiKey(req)
+		if err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
 		}
 
-		output.Success("Profile %q removed", name)
+		// From here on, undo the new key if we can't switch to it.
+		abandon := func(cause error) error {
+			if err := oldClient.RevokeApiKey(created.ID); err != nil {
+				output.Error("Could not revoke the new key %s: %v", created.ID, err)
+			}
+			return cause
+		}
+
+		keyClient := *oldClient
+		keyClient.APIKey = created.Key
+		if _, err := keyClient.GetMe(); err != nil {
+			return abandon(fmt.Errorf("new API key validation failed: %w", err))
+		}
+
+		err = config.Update(func(cfg *config.Config) error {
+			p, ok := cfg.Profiles[name]
+			if !ok {
+				return fmt.Errorf("profile %q no longer exists", name)
+			}
+			p.APIKey = created.Key
+			cfg.Profiles[name] = p
+			return nil
+		})
+		if err != nil {
+			return abandon(err)
+		}
+		output.Success("Profile %q now uses API key %s… (id: %s)", name, created.KeyPrefix, created.ID)
+
+		switch {
+		case old == nil:
+			output.Info("The previous key was not found in your key list; revoke it in the web UI if it is still active.")
+		case rotateKeepOld:
+			output.Info("Previous key %s… (id: %s) left active", old.KeyPrefix, old.ID)
+		default:
+			if err := keyClient.RevokeApiKey(old.ID); err != nil {
+				return fmt.Errorf("profile updated, but revoking the previous key failed: %w. Run: eg apikey revoke %s", err, old.ID)
+			}
+			output.Success("Previous key %s… revoked", old.KeyPrefix)
+		}
 		return nil
 	},
 }
 
 func init() {
+	for _, c := range []*cobra.Command{profileAddCmd, profileSetCmd} {
+		c.Flags().StringVar(&profileCAFile, "ca-file", "", "PEM bundle of extra CAs to trust (self-hosted servers)")
+		c.Flags().StringVar(&profileClientCert, "client-cert", "", "Client certificate for mutual TLS")
+		c.Flags().StringVar(&profileClientKey, "client-key", "", "Private key for --client-cert")
+		c.Flags().BoolVar(&profileInsecure, "insecure-skip-verify", false, "Disable TLS certificate verification (unsafe; prefer --ca-file)")
+		c.Flags().StringVar(&profileProxyURL, "proxy-url", "", "Proxy for this profile, overriding HTTPS_PROXY (\"direct\" for none)")
+		c.Flags().StringVar(&profileTunnelHost, "tunnel-host", "", "Edge host for tunnel resources, overriding the one the server reports")
+	}
+	profileAddCmd.MarkFlagsRequiredTogether("client-cert", "client-key")
+
+	profileRotateKeyCmd.Flags().StringVar(&rotateExpires, "expires", "", "Expire the new key after this long, e.g. 90d (default: never)")
+	profileRotateKeyCmd.Flags().BoolVar(&rotateKeepOld, "keep-old", false, "Do not revoke the previous key")
+	profileRotateKeyCmd.Flags().StringArrayVar(&rotateScopes, "scope", nil, "Scope to grant the new key instead of the previous key's (repeatable)")
+	profileRotateKeyCmd.Flags().BoolVarP(&rotateYes, "yes", "y", false, "Use the server's default scopes if the previous key's are unknown")
+	profileRotateKeyCmd.RegisterFlagCompletionFunc("scope", cobra.FixedCompletions([]string{api.ScopeAgentConnect}, cobra.ShellCompDirectiveNoFileComp))
+
 	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileSetCmd)
 	profileCmd.AddCommand(profileListCmd)
 	profileCmd.AddCommand(profileUseCmd)
 	profileCmd.AddCommand(profileRemoveCmd)
+	profileCmd.AddCommand(profileRotateKeyCmd)
 	rootCmd.AddCommand(profileCmd)
 }
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/cache"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptFormat    string
+	promptMaxAge    time.Duration
+	promptNoRefresh bool
+
+	promptRefreshWatch    bool
+	promptRefreshInterval time.Duration
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a compact session indicator for your shell prompt",
+	Long: `Print a compact indicator of the active session, e.g. "eg:prod 1h12m".
+
+This never calls the API: it reads the session cache written by every eg
+command that fetches sessions. When the cache is older than --max-age, a
+background "eg prompt refresh" is started so the next prompt is up to date.
+Prints nothing when there is no active session.
+
+Placeholders for --format: {profile}, {remaining}, {ip}, {id}, {count}.
+
+Set up your shell with: eg prompt init bash|zsh|fish|starship`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// A prompt must stay fast and quiet: no errors, no network.
+		name := resolveProfileName()
+		if name == "" {
+			return nil
+		}
+
+		now := time.Now()
+		state, err := cache.ReadSessions(name)
+		if !promptNoRefresh && (err != nil || state.Age(now) > promptMaxAge) {
+			spawnPromptRefresh(name)
+		}
+		if err != nil {
+			return nil
+		}
+
+		active := state.Active(now)
+		if len(active) == 0 {
+			return nil
+		}
+
+		// Show the session that keeps us whitelisted the longest
+		best := active[0]
+		bestRemaining, _ := best.Remaining(now)
+		for _, s := range active[1:] {
+			if d, ok := s.Remaining(now); ok && d > bestRemaining {
+				best, bestRemaining = s, d
+			}
+		}
+
+		fmt.Println(formatPrompt(promptFormat, name, &best, bestRemaining, len(active)))
+		return nil
+	},
+}
+
+var promptRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the session cache used by eg prompt",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if promptRefreshWatch && promptRefreshInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		if !promptRefreshWatch {
+			_, err := listSessions(client)
+			return err
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		ticker := time.NewTicker(promptRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := listSessions(client); err != nil {
+				fmt.Fprintf(os.Stderr, "refresh failed: %v\n", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-sigCh:
+				return nil
+			}
+		}
+	},
+}
+
+var promptInitCmd = &cobra.Command{
+	Use:       "init <bash|zsh|fish|starship>",
+	Short:     "Print shell integration for eg prompt",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish", "starship"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snippet, ok := promptSnippets[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell %q (use bash, zsh, fish or starship)", args[0])
+		}
+		fmt.Print(snippet)
+		return nil
+	},
+}
+
+var promptSnippets = map[string]string{
+	"bash": `# Add to ~/.bashrc:  eval "$(eg prompt init bash)"
+__eg_prompt() { EG_PROMPT="$(eg prompt 2>/dev/null)"; }
+PROMPT_COMMAND="__eg_prompt${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+PS1='${EG_PROMPT:+[$EG_PROMPT] }'"$PS1"
+`,
+	"zsh": `# Add to ~/.zshrc:  eval "$(eg prompt init zsh)"
+setopt PROMPT_SUBST
+RPROMPT='$(eg prompt 2>/dev/null)'"$RPROMPT"
+`,
+	"fish": `# Add to ~/.config/fish/config.fish:  eg prompt init fish | source
+function fish_right_prompt
+    eg prompt 2>/dev/null
+end
+`,
+	"starship": `# Add to ~/.config/starship.toml
+[custom.entryguard]
+command = "eg prompt"
+when = true
+format = "[$output]($style) "
+style = "bold green"
+`,
+}
+
+func formatPrompt(format, profile string, s *api.Session, remaining time.Duration, count int) string {
+	ip := s.Ipv4Address
+	if ip == "" {
+		ip = s.Ipv6Address
+	}
+	return strings.NewReplacer(
+		"{profile}", profile,
+		"{remaining}", formatCompactSpan(remaining),
+		"{ip}", ip,
+		"{id}", output.ShortID(s.ID),
+		"{count}", strconv.Itoa(count),
+	).Replace(format)
+}
+
+// spawnPromptRefresh starts a detached "eg prompt refresh" for the profile,
+// unless one was started recently.
+func spawnPromptRefresh(profile string) {
+	if recent, err := cache.Touch("refresh-"+profile, 30*time.Second); err != nil || recent {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	c := exec.Command(exe, "prompt", "refresh", "--profile", profile)
+	detach(c)
+	if err := c.Start(); err != nil {
+		return
+	}
+	c.Process.Release()
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", "eg:{profile} {remaining}", "Prompt format")
+	promptCmd.Flags().DurationVar(&promptMaxAge, "max-age", time.Minute, "Refresh the cache in the background when older than this")
+	promptCmd.Flags().BoolVar(&promptNoRefresh, "no-refresh", false, "Never start a background refresh")
+
+	promptRefreshCmd.Flags().BoolVar(&promptRefreshWatch, "watch", false, "Keep refreshing until interrupted")
+	promptRefreshCmd.Flags().DurationVar(&promptRefreshInterval, "interval", time.Minute, "Refresh interval for --watch")
+
+	promptCmd.AddCommand(promptRefreshCmd)
+	promptCmd.AddCommand(promptInitCmd)
+	rootCmd.AddCommand(promptCmd)
+}
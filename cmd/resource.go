@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/cache"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resourceName          string
+	resourceType          string
+	resourceIdentifier    string
+	resourceDescription   string
+	resourceAgent         string
+	resourceScriptDir     string
+	resourceScriptTimeout time.Duration
+	resourceEnabled       bool
+	resourceYes           bool
+)
+
+var resourceCmd = &cobra.Command{
+	Use:   "resource",
+	Short: "Manage organization resources (admin)",
+	Long: `Manage the resources sessions whitelist on.
+
+Listing and viewing resources works for every user; create, update and delete
+require organization admin rights. For agent-managed resources, --script-dir
+and --script-timeout are passed to the eg-agent with every APPLY/REVOKE
+command: the agent runs the scripts in <script-dir>/apply or <script-dir>/revoke.`,
+}
+
+var resourceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List resources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		resources, err := client.ListResources()
+		if err != nil {
+			return err
+		}
+		if name := resolveProfileName(); name != "" {
+			cache.WriteResources(name, resources)
+		}
+
+		if output.Format == "json" {
+			output.PrintJSON(resources)
+			return nil
+		}
+
+		var rows [][]string
+		for _, r := range resources {
+			rows = append(rows, []string{
+				output.ShortID(r.ID),
+				r.Name,
+				r.ResourceType,
+				valueOrDash(r.ResourceIdentifier),
+				valueOrDash(r.ScriptDir),
+				enabledText(r.Enabled),
+			})
+		}
+		output.PrintTable([]string{"ID", "NAME", "TYPE", "IDENTIFIER", "SCRIPT DIR", "ENABLED"}, rows)
+		return nil
+	},
+}
+
+var resourceGetCmd = &cobra.Command{
+	Use:               "get <name|id>",
+	Short:             "Show a resource",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeResourceNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+
+		r, err := resolveResource(client, args[0])
+		if err != nil {
+			return err
+		}
+		r, err = client.GetResource(r.ID)
+		if err != nil {
+			return err
+		}
+
+		if output.Format == "json" {
+			output.PrintJSON(r)
+			return nil
+		}
+		printResourceDetail(r)
+		return nil
+	},
+}
+
+var resourceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resourceName == "" || resourceType == "" {
+			return fmt.Errorf("--name and --type are required")
+		}
+		timeout, err := scriptTimeoutSeconds()
+		if err != nil {
+			return err
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		req := &api.ResourceRequest{
+			Name:               resourceName,
+			ResourceType:       resourceType,
+			ResourceIdentifier: resourceIdentifier,
+			Description:        resourceDescription,
+			AgentID:            resourceAgent,
+			Enabled:            resourceEnabled,
+		}
+		if resourceScriptDir != "" {
+			req.ScriptDir = &resourceScriptDir
+		}
+		if timeout > 0 {
+			req.ScriptTimeout = &timeout
+		}
+		r, err := client.CreateResource(req)
+		if err != nil {
+			return err
+		}
+		clearResourceCache()
+
+		if output.Format == "json" {
+			output.PrintJSON(r)
+			return nil
+		}
+		output.Success("Resource %q created (id: %s)", r.Name, r.ID)
+		return nil
+	},
+}
+
+var resourceUpdateCmd = &cobra.Command{
+	Use:               "update <name|id>",
+	Short:             "Update a resource",
+	Long:              "Update a resource. Only the flags given are changed; pass an empty value (e.g. --script-dir \"\") to clear a field.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeResourceNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timeout, err := scriptTimeoutSeconds()
+		if err != nil {
+			return err
+		}
+
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		existing, err := resolveResource(client, args[0])
+		if err != nil {
+			return err
+		}
+		existing, err = client.GetResource(existing.ID)
+		if err != nil {
+			return err
+		}
+
+		req := existing.Request()
+		flags := cmd.Flags()
+		if flags.Changed("name") {
+			req.Name = resourceName
+		}
+		if flags.Changed("type") {
+			req.ResourceType = resourceType
+		}
+		if flags.Changed("identifier") {
+			req.ResourceIdentifier = resourceIdentifier
+		}
+		if flags.Changed("description") {
+			req.Description = resourceDescription
+		}
+		if flags.Changed("agent") {
+			req.AgentID = resourceAgent
+		}
+		if flags.Changed("script-dir") {
+			req.ScriptDir = &resourceScriptDir
+		}
+		if flags.Changed("script-timeout") {
+			req.ScriptTimeout = &timeout
+		}
+		if flags.Changed("enabled") {
+			req.Enabled = resourceEnabled
+		}
+
+		r, err := client.UpdateResource(existing.ID, req)
+		if err != nil {
+			return err
+		}
+		clearResourceCache()
+
+		if output.Format == "json" {
+			output.PrintJSON(r)
+			return nil
+		}
+		output.Success("Resource %q updated", r.Name)
+		return nil
+	},
+}
+
+var resourceDeleteCmd = &cobra.Command{
+	Use:               "delete <name|id>",
+	Short:             "Delete a resource",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeResourceNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			return err
+		}
+		if _, err := requireOrgAdmin(client); err != nil {
+			return err
+		}
+
+		r, err := resolveResource(client, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !resourceYes {
+			ok, err := confirm(fmt.Sprintf("Delete resource %q (%s)?", r.Name, r.ID))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("aborted")
+			}
+		}
+
+		if err := client.DeleteResource(r.ID); err != nil {
+			return err
+		}
+		clearResourceCache()
+
+		output.Success("Resource %q deleted", r.Name)
+		return nil
+	},
+}
+
+// resolveResource finds a single resource by exact name or ID.
+func resolveResource(client *api.Client, input string) (*api.Resource, error) {
+	resources, err := client.ListResources()
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range resources {
+		if r.ID == input || strings.EqualFold(r.Name, input) {
+			return &resources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("resource '%s' not found. Run: eg resource list", input)
+}
+
+func printResourceDetail(r *api.Resource) {
+	fmt.Printf("Resource %s\n", r.ID)
+	fmt.Printf("  Name:        %s\n", r.Name)
+	fmt.Printf("  Type:        %s\n", r.ResourceType)
+	if r.ResourceIdentifier != "" {
+		fmt.Printf("  Identifier:  %s\n", r.ResourceIdentifier)
+	}
+	if r.Description != "" {
+		fmt.Printf("  Description: %s\n", r.Description)
+	}
+	fmt.Printf("  Enabled:     %s\n", enabledText(r.Enabled))
+	if r.AgentID != "" {
+		fmt.Printf("  Agent:       %s\n", r.AgentID)
+	}
+	if r.ScriptDir != "" {
+		fmt.Printf("  Script dir:  %s\n", r.ScriptDir)
+		timeout := "agent default"
+		if r.ScriptTimeout > 0 {
+			timeout = strconv.Itoa(r.ScriptTimeout) + "s"
+		}
+		fmt.Printf("  Timeout:     %s\n", timeout)
+	}
+	fmt.Printf("  Created:     %s\n", output.FormatTime(r.CreatedAt))
+	fmt.Printf("  Updated:     %s\n", output.FormatTime(r.UpdatedAt))
+}
+
+func enabledText(enabled bool) string {
+	if enabled {
+		return "yes"
+	}
+	return "no"
+}
+
+// scriptTimeoutSeconds returns --script-timeout in the whole seconds the
+// API takes; 0 means the agent's default.
+func scriptTimeoutSeconds() (int, error) {
+	d := resourceScriptTimeout
+	if d < 0 || d%time.Second != 0 {
+		return 0, fmt.Errorf("--script-timeout must be a whole number of seconds, or 0 for the agent default")
+	}
+	return int(d / time.Second), nil
+}
+
+func clearResourceCache() {
+	if name := resolveProfileName(); name != "" {
+		cache.ClearResources(name)
+	}
+}
+
+// resolveResources turns --resource / --exclude values (names, IDs or glob
+// patterns such as "db-*") into the resources to start a session on. It
+// returns nil when no selection was made, leaving the server to whitelist on
+// every resource.
+func resolveResources(client *api.Client, include, exclude []string) ([]api.Resource, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	all, err := client.ListResources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	selected := all
+	if len(include) > 0 {
+		selected = nil
+		seen := make(map[string]bool)
+		for _, name := range include {
+			matched, err := matchResources(all, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range matched {
+				if !seen[r.ID] {
+					seen[r.ID] = true
+					selected = append(selected, r)
+				}
+			}
+		}
+	}
+
+	if len(exclude) > 0 {
+		excluded := make(map[string]bool)
+		for _, name := range exclude {
+			matched, err := matchResources(all, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range matched {
+				excluded[r.ID] = true
+			}
+		}
+		var kept []api.Resource
+		for _, r := range selected {
+			if !excluded[r.ID] {
+				kept = append(kept, r)
+			}
+		}
+		selected = kept
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no resources left to whitelist after applying --resource/--exclude")
+	}
+	return selected, nil
+}
+
+// matchResources matches resources by exact ID, case-insensitive name or,
+// when input contains glob metacharacters, a case-insensitive name pattern.
+func matchResources(resources []api.Resource, input string) ([]api.Resource, error) {
+	if strings.ContainsAny(input, "*?[") {
+		pattern := strings.ToLower(input)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource pattern '%s': %w", input, err)
+		}
+		var matched []api.Resource
+		for _, r := range resources {
+			if ok, _ := path.Match(pattern, strings.ToLower(r.Name)); ok {
+				matched = append(matched, r)
+			}
+		}
+		if len(matched) > 0 {
+			return matched, nil
+		}
+	} else {
+		for _, r := range resources {
+			if r.ID == input || strings.EqualFold(r.Name, input) {
+				return []api.Resource{r}, nil
+			}
+		}
+	}
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown resource '%s' (available: %s)", input, strings.Join(names, ", "))
+}
+
+func resourceIDs(resources []api.Resource) []string {
+	ids := make([]string, 0, len(resources))
+	for _, r := range resources {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func resourceNames(resources []api.Resource) []string {
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func init() {
+	for _, c := range []*cobra.Command{resourceCreateCmd, resourceUpdateCmd} {
+		c.Flags().StringVar(&resourceName, "name", "", "Resource name")
+		c.Flags().StringVar(&resourceType, "type", "", "Resource type, e.g. AGENT, AWS_SECURITY_GROUP")
+		c.Flags().StringVar(&resourceIdentifier, "identifier", "", "Provider-specific identifier (e.g. security group ID)")
+		c.Flags().StringVar(&resourceDescription, "description", "", "Description")
+		c.Flags().StringVar(&resourceAgent, "agent", "", "ID of the eg-agent that manages this resource")
+		c.Flags().StringVar(&resourceScriptDir, "script-dir", "", "Agent script directory (contains apply/ and revoke/)")
+		c.Flags().DurationVar(&resourceScriptTimeout, "script-timeout", 0, "Per-script timeout on the agent, e.g. 30s (0 = agent default)")
+		c.Flags().BoolVar(&resourceEnabled, "enabled", true, "Whether sessions apply to this resource")
+	}
+	resourceDeleteCmd.Flags().BoolVarP(&resourceYes, "yes", "y", false, "Do not ask for confirmation")
+
+	resourceCmd.AddCommand(resourceListCmd)
+	resourceCmd.AddCommand(resourceGetCmd)
+	resourceCmd.AddCommand(resourceCreateCmd)
+	resourceCmd.AddCommand(resourceUpdateCmd)
+	resourceCmd.AddCommand(resourceDeleteCmd)
+	rootCmd.AddCommand(resourceCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+func TestResolveResources(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	for _, name := range []string{"db-prod", "db-staging", "web-prod", "Bastion"} {
+		srv.AddResource(entryguard.Resource{Name: name, ResourceType: "AWS_SG", Enabled: true})
+	}
+	client := api.NewClient(srv.URL, srv.APIKey)
+
+	all, err := client.ListResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idOf := make(map[string]string)
+	for _, r := range all {
+		idOf[r.Name] = r.ID
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string // resource names, in order; nil for no selection
+		wantErr string   // substring of the error; "" for none
+	}{
+		{"no selection", nil, nil, nil, ""},
+		{"by name", []string{"db-prod"}, nil, []string{"db-prod"}, ""},
+		{"by name, any case", []string{"bastion"}, nil, []string{"Bastion"}, ""},
+		{"by ID", []string{idOf["web-prod"]}, nil, []string{"web-prod"}, ""},
+		{"by glob", []string{"db-*"}, nil, []string{"db-prod", "db-staging"}, ""},
+		{"overlapping includes", []string{"db-*", "db-prod"}, nil, []string{"db-prod", "db-staging"}, ""},
+		{"exclude only", nil, []string{"*-prod"}, []string{"db-staging", "Bastion"}, ""},
+		{"exclude wins over include", []string{"db-*"}, []string{"db-staging"}, []string{"db-prod"}, ""},
+		{"exclude by ID", []string{"*prod"}, []string{idOf["db-prod"]}, []string{"web-prod"}, ""},
+		{"unknown name", []string{"cache"}, nil, nil, "unknown resource 'cache'"},
+		{"glob matching nothing", []string{"cache-*"}, nil, nil, "unknown resource 'cache-*'"},
+		{"invalid glob", []string{"db-["}, nil, nil, "invalid resource pattern"},
+		{"everything excluded", []string{"db-prod"}, []string{"db-*"}, nil, "no resources left"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveResources(client, tt.include, tt.exclude)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := resourceNames(got); strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/auth"
+	"github.com/entryguard-io/cli/internal/cache"
 	"github.com/entryguard-io/cli/internal/config"
 	"github.com/entryguard-io/cli/internal/output"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+const defaultAPIURL = "https://app.entryguard.io/api/v1"
+
 var (
 	profileFlag string
 	outputFlag  string
@@ -29,22 +40,56 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile to use (overrides default)")
-	rootCmd.PersistentFlags().StringVar(&outputFlag, "output", "table", "Output format: table or json")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile to use (overrides default); session start/stop/list and status accept a comma-separated list")
+	rootCmd.PersistentFlags().BoolVar(&allProfilesFlag, "all-profiles", false, "Run against every configured profile (session start/stop/list and status)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "table", "Output format: table or json")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
 }
 
 func SetVersion(v string) {
 	rootCmd.Version = v
+	api.UserAgent = "eg/" + v
 }
 
 func Execute() error {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.msg != "" {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		return err
 	}
 	return nil
 }
 
+// exitError carries a specific process exit status. Commands return it when
+// they have already reported the problem themselves (msg empty) or when the
+// status code matters to scripts, e.g. partial failure.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	if e.msg == "" {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.msg
+}
+
+// ExitCode maps an error returned by Execute to a process exit status.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return 1
+}
+
 func loadConfig() (*config.Config, error) {
 	cfg, err := config.Load()
 	if err != nil {
@@ -53,26 +98,165 @@ func loadConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
-func getClient() (*api.Client, error) {
+func getProfile() (*config.Profile, error) {
+	if isMultiProfile() {
+		return nil, fmt.Errorf("multiple profiles are only supported by: session start, session stop, session list, status, ssh-config")
+	}
 	cfg, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
-	profile, err := config.GetProfile(cfg, profileFlag)
+	return config.GetProfile(cfg, profileFlag)
+}
+
+// resolveProfileName returns the name of the profile in use: --profile if
+// given, otherwise the configured default.
+func resolveProfileName() string {
+	if isMultiProfile() {
+		return ""
+	}
+	if profileFlag != "" {
+		return profileFlag
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return ""
+	}
+	return cfg.DefaultProfile
+}
+
+// listSessions fetches the user's sessions and refreshes the local session
+// cache read by `eg prompt`. Cache failures never fail the command.
+func listSessions(client *api.Client) ([]api.Session, error) {
+	return listSessionsFor(resolveProfileName(), client)
+}
+
+// listSessionsFor is listSessions for an explicitly named profile.
+func listSessionsFor(profile string, client *api.Client) ([]api.Session, error) {
+	sessions, err := client.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		cache.WriteSessions(profile, sessions)
+	}
+	return sessions, nil
+}
+
+// cacheSessionFor records a session returned by start/stop/extend in the
+// profile's local session cache.
+func cacheSessionFor(profile string, s *api.Session) {
+	if profile != "" {
+		cache.UpsertSession(profile, s)
+	}
+}
+
+func getClient() (*api.Client, error) {
+	profile, err := getProfile()
 	if err != nil {
 		return nil, err
 	}
-	return api.NewClient(profile.APIURL, profile.APIKey), nil
+	return newClient(resolveProfileName(), profile)
 }
 
 func getClientUnauthenticated() *api.Client {
 	cfg, _ := loadConfig()
-	baseURL := "https://app.entryguard.io/api/v1"
 	if cfg != nil {
 		profile, err := config.GetProfile(cfg, profileFlag)
 		if err == nil {
-			baseURL = profile.APIURL
+			p := *profile
+			p.APIKey, p.AccessToken, p.RefreshToken = "", "", ""
+			if client, err := newClient(profileFlag, &p); err == nil {
+				return client
+			}
+		}
+	}
+	return api.NewClient(defaultAPIURL, "")
+}
+
+var insecureWarned sync.Map
+
+// newClient builds an API client honouring the profile's connection
+// settings. Disabling certificate verification is announced on stderr on
+// every run, once per profile.
+func newClient(name string, profile *config.Profile) (*api.Client, error) {
+	if profile.InsecureSkipVerify {
+		if _, warned := insecureWarned.LoadOrStore(name, true); !warned {
+			fmt.Fprintln(os.Stderr, color.RedString("WARNING: TLS certificate verification is disabled for profile %q (insecure_skip_verify).", name))
+			fmt.Fprintln(os.Stderr, color.RedString("         Anyone on the network path can intercept your API key. Use ca_file instead."))
 		}
 	}
-	return api.NewClient(baseURL, "")
+	client, err := api.NewClientWithTransport(profile.APIURL, profile.APIKey, transportConfig(profile))
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	if profile.UsesLogin() {
+		src := auth.NewSource(loginConfig(profile.APIURL, client.HTTPClient), profileToken(profile))
+		src.OnRefresh = func(token *auth.Token) {
+			if err := saveLoginToken(name, token); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not save refreshed login for profile %q: %v\n", name, err)
+			}
+		}
+		client.Tokens = src
+	}
+	return client, nil
+}
+
+func transportConfig(profile *config.Profile) api.TransportConfig {
+	return api.TransportConfig{
+		CAFile:             profile.CAFile,
+		ClientCert:         profile.ClientCert,
+		ClientKey:          profile.ClientKey,
+		InsecureSkipVerify: profile.InsecureSkipVerify,
+		ProxyURL:           profile.ProxyURL,
+	}
+}
+
+// detectIPs detects the public IP addresses through the connection settings
+// of the profile in use (the first one when several are targeted), falling
+// back to a plain client when no profile is configured.
+func detectIPs(ctx context.Context) (ipv4, ipv6 string) {
+	if cfg, err := loadConfig(); err == nil {
+		if names, err := targetProfiles(cfg); err == nil {
+			profile := cfg.Profiles[names[0]]
+			if client, err := newClient(names[0], &profile); err == nil {
+				return client.WithContext(ctx).DetectPublicIPs()
+			}
+		}
+	}
+	return api.DetectIPsContext(ctx)
+}
+
+// requireOrgAdmin fails with a clear message unless the API key belongs to an
+// organization admin. The server enforces this too; checking first avoids a
+// bare HTTP 403.
+func requireOrgAdmin(client *api.Client) (*api.UserInfo, error) {
+	user, err := client.GetMe()
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsOrgAdmin {
+		return nil, fmt.Errorf("this command requires organization admin rights (signed in as %s in %s)", user.Email, user.OrganizationName)
+	}
+	return user, nil
+}
+
+// confirm asks a yes/no question on the terminal. It refuses to guess when
+// stdin is not a terminal; callers offer --yes for scripts.
+func confirm(prompt string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("refusing to proceed without confirmation; re-run with --yes")
+	}
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// valueOrDash shows empty table cells as "-".
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
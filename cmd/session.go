@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/lifecycle"
 	"github.com/entryguard-io/cli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -15,38 +22,83 @@ var sessionCmd = &cobra.Command{
 }
 
 var (
-	sessionDuration int
+	sessionDuration string
+	sessionUntil    string
 	sessionIPv4     string
 	sessionIPv6     string
+	sessionInclude  []string
+	sessionExclude  []string
+	sessionReason   string
+	sessionTicket   string
+	sessionPreset   string
+	sessionV4Prefix int
+	sessionV6Prefix int
 	extendHours     int
+	extendFor       string
+	extendUntil     string
+	sessionOrg      bool
+	sessionUser     string
+	sessionYes      bool
 )
 
 var sessionStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start a new IP whitelisting session",
+	Long: `Start a new IP whitelisting session.
+
+The length can be given as a duration (--duration 45m, --for 1h30m, --duration 2d)
+or as a wall-clock end time (--until 18:00). Whole-hour durations are sent to the
+API as hours; anything else is rounded up to the next whole minute.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
+		multi := isMultiProfile()
+
+		reason := strings.TrimSpace(sessionReason)
+		if sessionPreset != "" {
+			preset, err := applyPreset(cmd, sessionPreset)
+			if err != nil {
+				return err
+			}
+			reason, err = preset.RenderReason(config.ReasonData{
+				Reason: reason,
+				Ticket: strings.TrimSpace(sessionTicket),
+				Preset: sessionPreset,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := checkPrefixLengths(sessionV4Prefix, sessionV6Prefix); err != nil {
 			return err
 		}
 
-		req := &api.StartSessionRequest{}
-		if sessionDuration > 0 {
-			req.DurationHours = &sessionDuration
+		base := api.StartSessionRequest{
+			Reason:        reason,
+			TicketRef:     strings.TrimSpace(sessionTicket),
+			Ipv4PrefixLen: sessionV4Prefix,
+			Ipv6PrefixLen: sessionV6Prefix,
+			Ipv4Address:   sessionIPv4,
+			Ipv6Address:   sessionIPv6,
 		}
-		if sessionIPv4 != "" {
-			req.Ipv4Address = sessionIPv4
+
+		length, err := sessionLength(sessionDuration, sessionUntil, time.Now())
+		if err != nil {
+			return err
 		}
-		if sessionIPv6 != "" {
-			req.Ipv6Address = sessionIPv6
+		if length > 0 {
+			hours, minutes := duration.Split(length)
+			if hours > 0 {
+				base.DurationHours = &hours
+			} else {
+				base.DurationMinutes = &minutes
+			}
 		}
 
 		// Auto-detect IPs when no flags provided
 		if sessionIPv4 == "" && sessionIPv6 == "" {
 			output.Info("Detecting IP addresses...")
-			ipv4, ipv6 := api.DetectIPs()
-			req.Ipv4Address = ipv4
-			req.Ipv6Address = ipv6
+			ipv4, ipv6 := detectIPs(context.Background())
+			base.Ipv4Address = ipv4
+			base.Ipv6Address = ipv6
 			if ipv4 != "" && ipv6 != "" {
 				output.Info("Detected IPv4: %s, IPv6: %s", ipv4, ipv6)
 			} else if ipv4 != "" {
@@ -58,119 +110,264 @@ var sessionStartCmd = &cobra.Command{
 			}
 		}
 
-		output.Info("Starting session...")
-		session, err := client.StartSession(req)
+		results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+			if err := profile.CheckReason(reason); err != nil {
+				return nil, err
+			}
+
+			req := base
+			resources, err := resolveResources(client, sessionInclude, sessionExclude)
+			if err != nil {
+				return nil, err
+			}
+			if resources != nil {
+				req.ResourceIDs = resourceIDs(resources)
+				if !multi {
+					output.Info("Limiting session to %d resource(s): %s", len(resources), strings.Join(resourceNames(resources), ", "))
+				}
+			}
+
+			if !multi {
+				output.Info("Starting session...")
+			}
+			session, err := client.StartSession(&req)
+			if err != nil {
+				return nil, err
+			}
+			cacheSessionFor(name, session)
+			runHooks(name, profile, lifecycle.Started, session)
+			return viewSession(session, profile), nil
+		})
 		if err != nil {
 			return err
 		}
 
-		if output.Format == "json" {
-			output.PrintJSON(session)
-			return nil
-		}
-
-		output.Success("Session started")
-		printSessionSummary(session)
-		return nil
+		return printProfileResults(results, func(v any) {
+			output.Success("Session started")
+			printSessionSummary(v.(sessionView))
+		})
 	},
 }
 
 var sessionStopCmd = &cobra.Command{
-	Use:   "stop [id]",
-	Short: "Stop a session (defaults to most recent active)",
-	Args:  cobra.MaximumNArgs(1),
+	Use:               "stop [id|latest|@N]",
+	Short:             "Stop a session (defaults to most recent active)",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSessionIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
+		multi := isMultiProfile()
+		if sessionOrg && len(args) == 0 {
+			return fmt.Errorf("--org needs the ID of the session to stop. Run: eg session list --org")
+		}
+		if sessionOrg && multi && !sessionYes {
+			// Profiles run in parallel, so there is no way to ask for each.
+			return fmt.Errorf("--org with several profiles needs --yes")
 		}
 
-		var sessionID string
-		if len(args) > 0 {
-			resolved, err := resolveSessionID(client, args[0])
-			if err != nil {
-				return err
-			}
-			sessionID = resolved
-		} else {
-			sessions, err := client.ListSessions()
-			if err != nil {
-				return err
+		results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+			if sessionOrg {
+				return stopOrgSession(client, args[0], multi)
 			}
-			for _, s := range sessions {
-				if s.Status == "ACTIVE" || s.Status == "PARTIAL" {
-					sessionID = s.ID
-					break
+
+			var sessionID string
+			if len(args) > 0 {
+				resolved, err := resolveSessionIDFor(name, client, args[0])
+				if err != nil {
+					return nil, err
+				}
+				sessionID = resolved
+			} else {
+				sessions, err := listSessionsFor(name, client)
+				if err != nil {
+					return nil, err
+				}
+				for _, s := range sessions {
+					if s.IsActive() {
+						sessionID = s.ID
+						break
+					}
+				}
+				if sessionID == "" {
+					return nil, fmt.Errorf("no active session found")
 				}
 			}
-			if sessionID == "" {
-				return fmt.Errorf("no active session found")
-			}
-		}
 
-		output.Info("Stopping session %s...", sessionID[:8])
-		session, err := client.StopSession(sessionID)
+			if !multi {
+				output.Info("Stopping session %s...", output.ShortID(sessionID))
+			}
+			session, err := client.StopSession(sessionID)
+			if err != nil {
+				return nil, err
+			}
+			cacheSessionFor(name, session)
+			runHooks(name, profile, lifecycle.Ended, session)
+			return session, nil
+		})
 		if err != nil {
 			return err
 		}
 
-		if output.Format == "json" {
-			output.PrintJSON(session)
-			return nil
-		}
-
-		output.Success("Session stopped")
-		return nil
+		return printProfileResults(results, func(v any) {
+			output.Success("Session %s stopped", output.ShortID(v.(*api.Session).ID))
+		})
 	},
 }
 
 var sessionListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List your sessions",
+	Long: `List your sessions.
+
+Organization admins can pass --org to list the sessions of every user in the
+organization, optionally narrowed with --user.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
-		if err != nil {
-			return err
+		if sessionUser != "" && !sessionOrg {
+			return fmt.Errorf("--user requires --org")
 		}
 
-		sessions, err := client.ListSessions()
+		results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+			var sessions []api.Session
+			var err error
+			if sessionOrg {
+				sessions, err = listOrgSessions(client, sessionUser)
+			} else {
+				sessions, err = listSessionsFor(name, client)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if output.Format == "json" {
+				return viewSessions(sessions, profile), nil
+			}
+			return sessions, nil
+		})
 		if err != nil {
 			return err
 		}
 
-		if output.Format == "json" {
-			output.PrintJSON(sessions)
-			return nil
+		return printProfileResults(results, func(v any) {
+			printSessionTable(v.([]api.Session), sessionOrg)
+		})
+	},
+}
+
+// printSessionTable prints sessions as a table; withUser adds a USER column
+// for listings that span the organization.
+func printSessionTable(sessions []api.Session, withUser bool) {
+	headers := []string{"ID", "STATUS", "IP", "STARTED", "REMAINING", "REASON"}
+	if withUser {
+		headers = append([]string{"ID", "USER"}, headers[1:]...)
+	}
+
+	var rows [][]string
+	for _, s := range sessions {
+		row := []string{
+			output.ShortID(s.ID),
+			output.StatusColor(s.Status),
+			sessionIPs(&s),
+			output.FormatTime(s.StartedAt),
+			output.FormatDuration(s.ExpiresAt),
+			truncate(sessionReasonText(&s), 40),
+		}
+		if withUser {
+			row = append([]string{row[0], sessionUserText(&s)}, row[1:]...)
 		}
+		rows = append(rows, row)
+	}
+	output.PrintTable(headers, rows)
+}
 
-		var rows [][]string
-		for _, s := range sessions {
-			ip := s.Ipv4Address
-			if ip == "" {
-				ip = s.Ipv6Address
-			}
-			if s.Ipv4Address != "" && s.Ipv6Address != "" {
-				ip = s.Ipv4Address + ", " + s.Ipv6Address
-			}
-			rows = append(rows, []string{
-				s.ID[:8],
-				output.StatusColor(s.Status),
-				ip,
-				output.FormatTime(s.StartedAt),
-				output.FormatDuration(s.ExpiresAt),
-			})
+// listOrgSessions lists every user's sessions after checking the caller is
+// an org admin. The server filters by user; the result is filtered again
+// only if it evidently ignored the parameter, as sessions may not carry the
+// field the server matched on.
+func listOrgSessions(client *api.Client, user string) ([]api.Session, error) {
+	if _, err := requireOrgAdmin(client); err != nil {
+		return nil, err
+	}
+	sessions, err := client.ListOrgSessions(user)
+	if err != nil {
+		return nil, err
+	}
+	if user != "" && api.IgnoredUserFilter(sessions, user) {
+		sessions = api.SessionsForUser(sessions, user)
+	}
+	return sessions, nil
+}
+
+// checkPrefixLengths validates --ipv4-prefix and --ipv6-prefix; 0 leaves
+// the prefix length to the server.
+func checkPrefixLengths(v4, v6 int) error {
+	if v4 < 0 || v4 > 32 {
+		return fmt.Errorf("--ipv4-prefix must be between 0 (unset) and 32")
+	}
+	if v6 < 0 || v6 > 128 {
+		return fmt.Errorf("--ipv6-prefix must be between 0 (unset) and 128")
+	}
+	return nil
+}
+
+// stopOrgSession stops another user's session. Unlike the user's own
+// sessions, it must be named by its full ID: a prefix or "latest" resolved
+// across the whole organization could easily pick someone else's session.
+// It asks for confirmation unless --yes is given.
+func stopOrgSession(client *api.Client, input string, quiet bool) (*api.Session, error) {
+	input = strings.TrimSpace(input)
+	if !api.IsUUID(input) {
+		return nil, fmt.Errorf("--org needs a full session ID, not %q. Run: eg session list --org", input)
+	}
+	sessions, err := listOrgSessions(client, "")
+	if err != nil {
+		return nil, err
+	}
+	var target *api.Session
+	for i := range sessions {
+		if strings.EqualFold(sessions[i].ID, input) {
+			target = &sessions[i]
+			break
 		}
-		output.PrintTable([]string{"ID", "STATUS", "IP", "STARTED", "REMAINING"}, rows)
-		return nil
-	},
+	}
+	if target == nil {
+		return nil, fmt.Errorf("no session %s in the organization", input)
+	}
+
+	if !sessionYes {
+		ok, err := confirm(fmt.Sprintf("Stop session %s of %s?", output.ShortID(target.ID), sessionUserText(target)))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("aborted")
+		}
+	}
+	if !quiet {
+		output.Info("Stopping session %s of %s...", output.ShortID(target.ID), sessionUserText(target))
+	}
+	return client.StopOrgSession(target.ID)
+}
+
+func sessionUserText(s *api.Session) string {
+	switch {
+	case s.UserEmail != "":
+		return s.UserEmail
+	case s.UserName != "":
+		return s.UserName
+	default:
+		return "-"
+	}
 }
 
 var sessionGetCmd = &cobra.Command{
-	Use:   "get <id>",
-	Short: "Get session details",
-	Args:  cobra.ExactArgs(1),
+	Use:               "get <id|latest|@N>",
+	Short:             "Get session details",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		client, err := newClient(resolveProfileName(), profile)
 		if err != nil {
 			return err
 		}
@@ -186,25 +383,36 @@ var sessionGetCmd = &cobra.Command{
 		}
 
 		if output.Format == "json" {
-			output.PrintJSON(session)
+			output.PrintJSON(viewSession(session, profile))
 			return nil
 		}
 
-		printSessionDetail(session)
+		printSessionDetail(session, profile)
 		return nil
 	},
 }
 
 var sessionExtendCmd = &cobra.Command{
-	Use:   "extend <id>",
+	Use:   "extend <id|latest|@N>",
 	Short: "Extend an active session",
-	Args:  cobra.ExactArgs(1),
+	Long: `Extend an active session by a duration (--for 30m, --hours 2) or up to a
+wall-clock time (--until 18:00). Durations are rounded up to whole minutes.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if extendHours <= 0 {
-			return fmt.Errorf("--hours is required and must be positive")
+		if extendHours < 0 {
+			return fmt.Errorf("--hours must be positive")
+		}
+		if extendHours == 0 && extendFor == "" && extendUntil == "" {
+			return fmt.Errorf("one of --for, --until or --hours is required")
 		}
 
-		client, err := getClient()
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		name := resolveProfileName()
+		client, err := newClient(name, profile)
 		if err != nil {
 			return err
 		}
@@ -214,14 +422,39 @@ var sessionExtendCmd = &cobra.Command{
 			return err
 		}
 
-		output.Info("Extending session %s by %d hours...", sessionID[:8], extendHours)
-		session, err := client.ExtendSession(sessionID, extendHours)
+		var additional time.Duration
+		switch {
+		case extendHours > 0:
+			additional = time.Duration(extendHours) * time.Hour
+		case extendFor != "":
+			additional, err = duration.Parse(extendFor)
+			if err != nil {
+				return fmt.Errorf("invalid --for: %w", err)
+			}
+		default:
+			current, err := client.GetSession(sessionID)
+			if err != nil {
+				return err
+			}
+			additional, err = extensionUntil(current.ExpiresAt, extendUntil, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		req := &api.ExtendSessionRequest{}
+		req.AdditionalHours, req.AdditionalMinutes = duration.Split(additional)
+
+		output.Info("Extending session %s by %s...", output.ShortID(sessionID), output.FormatSpan(additional))
+		session, err := client.ExtendSession(sessionID, req)
 		if err != nil {
 			return err
 		}
+		cacheSessionFor(name, session)
+		runHooks(name, profile, lifecycle.Extended, session)
 
 		if output.Format == "json" {
-			output.PrintJSON(session)
+			output.PrintJSON(viewSession(session, profile))
 			return nil
 		}
 
@@ -231,37 +464,73 @@ var sessionExtendCmd = &cobra.Command{
 	},
 }
 
-// resolveSessionID resolves a full or prefix session ID to the full UUID.
-// If the input is already a full UUID (36 chars), it's returned as-is.
-// Otherwise, it fetches the session list and matches by prefix.
+// sessionLength resolves --duration/--for or --until into a session length.
+// It returns 0 when neither was given so the server default applies.
+func sessionLength(durationFlag, untilFlag string, now time.Time) (time.Duration, error) {
+	switch {
+	case durationFlag != "":
+		d, err := duration.Parse(durationFlag)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --duration: %w", err)
+		}
+		return d, nil
+	case untilFlag != "":
+		d, err := duration.Until(untilFlag, now)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --until: %w", err)
+		}
+		return d, nil
+	default:
+		return 0, nil
+	}
+}
+
+// extensionUntil computes how much to add to a session expiring at expiresAt
+// so that it ends at the wall-clock time given by --until, counting clock
+// times from the expiry rather than from now.
+func extensionUntil(expiresAt, untilFlag string, now time.Time) (time.Duration, error) {
+	expiry, err := time.Parse(time.RFC3339Nano, expiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("session has no valid expiry to extend from")
+	}
+	d, err := duration.Extension(expiry, untilFlag, now.Location())
+	if err != nil {
+		return 0, fmt.Errorf("invalid --until: %w", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("session already runs until %s", output.FormatTime(expiresAt))
+	}
+	return d, nil
+}
+
+// resolveSessionID resolves a session reference (full UUID, ID prefix,
+// "latest" or "@N") to the full UUID. Full UUIDs are returned without an API
+// call; everything else is matched against the session list, see
+// api.MatchSession.
 func resolveSessionID(client *api.Client, input string) (string, error) {
-	if len(input) >= 36 {
+	return resolveSessionIDFor(resolveProfileName(), client, input)
+}
+
+// resolveSessionIDFor is resolveSessionID for an explicitly named profile.
+func resolveSessionIDFor(profile string, client *api.Client, input string) (string, error) {
+	if api.IsUUID(input) {
 		return input, nil
 	}
 
-	sessions, err := client.ListSessions()
+	sessions, err := listSessionsFor(profile, client)
 	if err != nil {
 		return "", err
 	}
 
-	var matches []string
-	for _, s := range sessions {
-		if strings.HasPrefix(s.ID, input) {
-			matches = append(matches, s.ID)
-		}
-	}
-
-	switch len(matches) {
-	case 0:
-		return "", fmt.Errorf("no session found matching '%s'", input)
-	case 1:
-		return matches[0], nil
-	default:
-		return "", fmt.Errorf("ambiguous session ID '%s' — matches %d sessions", input, len(matches))
+	s, err := api.MatchSession(sessions, input)
+	if err != nil {
+		return "", err
 	}
+	return s.ID, nil
 }
 
-func printSessionSummary(s *api.Session) {
+func printSessionSummary(v sessionView) {
+	s := v.Session
 	fmt.Printf("  ID:        %s\n", s.ID)
 	fmt.Printf("  Status:    %s\n", output.StatusColor(s.Status))
 	if s.Ipv4Address != "" {
@@ -276,17 +545,16 @@ func printSessionSummary(s *api.Session) {
 	}
 
 	// Show tunnel connection details
-	tunnels := getTunnelResources(s.ResourceIps)
-	if len(tunnels) > 0 {
+	if len(v.Tunnels) > 0 {
 		fmt.Println()
 		fmt.Println("  Tunnel resources:")
-		for _, t := range tunnels {
-			fmt.Printf("    %-20s %s\n", t.name, t.connection)
+		for _, t := range v.Tunnels {
+			fmt.Printf("    %-20s %s\n", t.Resource, t.connection())
 		}
 	}
 }
 
-func printSessionDetail(s *api.Session) {
+func printSessionDetail(s *api.Session, profile *config.Profile) {
 	fmt.Printf("Session %s\n", s.ID)
 	fmt.Printf("  Status:    %s\n", output.StatusColor(s.Status))
 	fmt.Printf("  User:      %s (%s)\n", s.UserName, s.UserEmail)
@@ -301,16 +569,28 @@ func printSessionDetail(s *api.Session) {
 	if s.EndedAt != "" {
 		fmt.Printf("  Ended:     %s (%s)\n", output.FormatTime(s.EndedAt), s.EndedReason)
 	}
+	if s.Reason != "" {
+		fmt.Printf("  Reason:    %s\n", s.Reason)
+	}
+	if s.TicketRef != "" {
+		fmt.Printf("  Ticket:    %s\n", s.TicketRef)
+	}
 
 	if len(s.ResourceIps) > 0 {
 		fmt.Println()
 		var rows [][]string
 		for _, r := range s.ResourceIps {
 			name := r.ResourceName
-			if isTunnelResource(r) {
-				port := parseTunnelPort(r.ProviderRuleId)
-				if port != "" {
-					name += "  edge.entryguard.io:" + port
+			if r.IsTunnel() {
+				tun, err := r.Tunnel()
+				host, hostErr := tunnelHost(profile, &r)
+				switch {
+				case err != nil:
+					name += "  (invalid tunnel rule)"
+				case hostErr != nil:
+					name += "  (invalid tunnel host)"
+				default:
+					name += "  " + net.JoinHostPort(host, strconv.Itoa(tun.Port))
 				}
 			}
 			rows = append(rows, []string{
@@ -325,50 +605,163 @@ func printSessionDetail(s *api.Session) {
 	}
 }
 
+// sessionReasonText combines the ticket reference and reason for table display.
+func sessionReasonText(s *api.Session) string {
+	switch {
+	case s.TicketRef != "" && s.Reason != "":
+		return "[" + s.TicketRef + "] " + s.Reason
+	case s.TicketRef != "":
+		return "[" + s.TicketRef + "]"
+	case s.Reason != "":
+		return s.Reason
+	default:
+		return "-"
+	}
+}
+
+// sessionIPs joins a session's IPv4 and IPv6 addresses for table display.
+func sessionIPs(s *api.Session) string {
+	switch {
+	case s.Ipv4Address != "" && s.Ipv6Address != "":
+		return s.Ipv4Address + ", " + s.Ipv6Address
+	case s.Ipv4Address != "":
+		return s.Ipv4Address
+	default:
+		return s.Ipv6Address
+	}
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// tunnelInfo is the edge endpoint of a tunnel resource in a session, with
+// the source CIDRs allowed through it.
 type tunnelInfo struct {
-	name       string
-	connection string
+	Resource   string   `json:"resource"`
+	ResourceID string   `json:"resourceId"`
+	Host       string   `json:"host"`
+	Port       int      `json:"port"`
+	CIDRs      []string `json:"cidrs"`
+}
+
+func (t tunnelInfo) connection() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// tunnelHost returns the edge host of a tunnel rule: the profile's
+// tunnel_host if set, else the host the API reported, else the EntryGuard
+// cloud edge. The host is passed to ssh, so it must be a bare host name or
+// IP address.
+func tunnelHost(profile *config.Profile, r *api.SessionResourceIp) (string, error) {
+	host, source := api.DefaultTunnelHost, ""
+	switch {
+	case profile != nil && profile.TunnelHost != "":
+		host, source = profile.TunnelHost, "the profile's tunnel_host"
+	case r.TunnelHost != "":
+		host, source = r.TunnelHost, "the server"
+	}
+	if err := api.ValidateTunnelHost(host); err != nil {
+		return "", fmt.Errorf("%w (from %s)", err, source)
+	}
+	return host, nil
+}
+
+// checkTunnelHost validates the profile's tunnel_host, if set, so a bad
+// value is reported instead of its tunnels silently going missing.
+func checkTunnelHost(profile *config.Profile) error {
+	if profile.TunnelHost == "" {
+		return nil
+	}
+	if err := api.ValidateTunnelHost(profile.TunnelHost); err != nil {
+		return fmt.Errorf("profile tunnel_host: %w", err)
+	}
+	return nil
 }
 
-func getTunnelResources(resourceIps []api.SessionResourceIp) []tunnelInfo {
-	seen := make(map[string]bool)
+// getTunnelResources returns the tunnel endpoints of rules, one per resource
+// and endpoint. Rules with malformed tunnel IDs or hosts are skipped.
+func getTunnelResources(profile *config.Profile, rules []api.SessionResourceIp) []tunnelInfo {
 	var tunnels []tunnelInfo
-	for _, r := range resourceIps {
-		if !isTunnelResource(r) || seen[r.ResourceName] {
+	index := make(map[string]int)
+	for i := range rules {
+		r := &rules[i]
+		tun, err := r.Tunnel()
+		if err != nil {
 			continue
 		}
-		seen[r.ResourceName] = true
-		port := parseTunnelPort(r.ProviderRuleId)
-		if port != "" {
-			tunnels = append(tunnels, tunnelInfo{
-				name:       r.ResourceName,
-				connection: "edge.entryguard.io:" + port,
-			})
+		host, err := tunnelHost(profile, r)
+		if err != nil {
+			continue
+		}
+		t := tunnelInfo{
+			Resource:   r.ResourceName,
+			ResourceID: r.ResourceID,
+			Host:       host,
+			Port:       tun.Port,
+		}
+		key := t.ResourceID + " " + t.connection()
+		n, ok := index[key]
+		if !ok {
+			n = len(tunnels)
+			index[key] = n
+			tunnels = append(tunnels, t)
 		}
+		tunnels[n].CIDRs = append(tunnels[n].CIDRs, tun.CIDR.String())
 	}
 	return tunnels
 }
 
-func isTunnelResource(r api.SessionResourceIp) bool {
-	return strings.HasPrefix(r.ProviderRuleId, "tunnel:")
+// sessionView is a session as eg prints it: the API fields plus the tunnel
+// endpoints resolved for the profile, which JSON output includes too.
+type sessionView struct {
+	*api.Session
+	Tunnels []tunnelInfo `json:"tunnels,omitempty"`
+}
+
+func viewSession(s *api.Session, profile *config.Profile) sessionView {
+	return sessionView{Session: s, Tunnels: getTunnelResources(profile, s.ResourceIps)}
 }
 
-func parseTunnelPort(providerRuleId string) string {
-	// Format: "tunnel:<port>:<cidr>"
-	parts := strings.SplitN(providerRuleId, ":", 3)
-	if len(parts) >= 2 {
-		return parts[1]
+func viewSessions(sessions []api.Session, profile *config.Profile) []sessionView {
+	views := make([]sessionView, len(sessions))
+	for i := range sessions {
+		views[i] = viewSession(&sessions[i], profile)
 	}
-	return ""
+	return views
 }
 
 func init() {
-	sessionStartCmd.Flags().IntVar(&sessionDuration, "duration", 0, "Session duration in hours")
+	sessionStartCmd.Flags().StringVar(&sessionDuration, "duration", "", "Session length, e.g. 45m, 1h30m, 2d (bare numbers are hours)")
+	sessionStartCmd.Flags().StringVar(&sessionDuration, "for", "", "Alias for --duration")
+	sessionStartCmd.Flags().StringVar(&sessionUntil, "until", "", "End the session at a wall-clock time, e.g. 18:00")
+	sessionStartCmd.Flags().IntVar(&sessionV4Prefix, "ipv4-prefix", 0, "Whitelist the IPv4 address as a /N network (e.g. 24)")
+	sessionStartCmd.Flags().IntVar(&sessionV6Prefix, "ipv6-prefix", 0, "Whitelist the IPv6 address as a /N network (e.g. 64)")
+	sessionStartCmd.Flags().StringVar(&sessionPreset, "preset", "", "Start from a named preset (see: eg preset list)")
+	sessionStartCmd.MarkFlagsMutuallyExclusive("duration", "for", "until")
 	sessionStartCmd.Flags().StringVar(&sessionIPv4, "ipv4", "", "IPv4 address to whitelist")
 	sessionStartCmd.Flags().StringVar(&sessionIPv6, "ipv6", "", "IPv6 address to whitelist")
+	sessionStartCmd.Flags().StringArrayVar(&sessionInclude, "resource", nil, "Only whitelist on this resource (name or ID, repeatable)")
+	sessionStartCmd.Flags().StringArrayVar(&sessionExclude, "exclude", nil, "Skip this resource (name or ID, repeatable)")
+	sessionStartCmd.Flags().StringVar(&sessionReason, "reason", "", "Why access is needed (recorded for audit)")
+	sessionStartCmd.Flags().StringVar(&sessionTicket, "ticket", "", "Ticket or incident reference, e.g. INC-1234")
+	sessionStartCmd.RegisterFlagCompletionFunc("resource", completeResourceNames)
+	sessionStartCmd.RegisterFlagCompletionFunc("exclude", completeResourceNames)
+	sessionStartCmd.RegisterFlagCompletionFunc("preset", completePresetNames)
 
 	sessionExtendCmd.Flags().IntVar(&extendHours, "hours", 0, "Hours to extend")
-	sessionExtendCmd.MarkFlagRequired("hours")
+	sessionExtendCmd.Flags().StringVar(&extendFor, "for", "", "Duration to extend by, e.g. 30m, 1h30m")
+	sessionExtendCmd.Flags().StringVar(&extendUntil, "until", "", "Extend until a wall-clock time, e.g. 18:00")
+	sessionExtendCmd.MarkFlagsMutuallyExclusive("hours", "for", "until")
+
+	sessionListCmd.Flags().BoolVar(&sessionOrg, "org", false, "List the sessions of every user in the organization (admin)")
+	sessionListCmd.Flags().StringVar(&sessionUser, "user", "", "With --org, only this user's sessions (email, name or ID)")
+	sessionStopCmd.Flags().BoolVar(&sessionOrg, "org", false, "Stop another user's session, given its full ID (admin)")
+	sessionStopCmd.Flags().BoolVarP(&sessionYes, "yes", "y", false, "Do not ask for confirmation with --org")
 
 	sessionCmd.AddCommand(sessionStartCmd)
 	sessionCmd.AddCommand(sessionStopCmd)
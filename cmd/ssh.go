@@ -0,0 +1,512 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/lifecycle"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/entryguard-io/cli/internal/sshconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sshConfigFile   string
+	sshConfigPrint  bool
+	sshConfigRemove bool
+	sshConfigUser   string
+	sshPrefix       string
+
+	sshLogin    string
+	sshReason   string
+	sshTicket   string
+	sshDuration string
+	sshWait     time.Duration
+)
+
+// sshTunnel is an active tunnel resource as an ssh destination.
+type sshTunnel struct {
+	Resource   string `json:"resource"`
+	ResourceID string `json:"resourceId"`
+	Alias      string `json:"alias"`
+	HostName   string `json:"hostName"`
+	Port       int    `json:"port"`
+	User       string `json:"user,omitempty"`
+	SessionID  string `json:"sessionId"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+var sshConfigCmd = &cobra.Command{
+	Use:   "ssh-config",
+	Short: "Write Host entries for active tunnel resources to ~/.ssh/config",
+	Long: `Write a Host entry for every tunnel resource of your active sessions into a
+managed block of ~/.ssh/config, so plain ssh, scp and rsync can reach them:
+
+  Host eg-bastion
+      HostName edge.entryguard.io
+      Port 41022
+
+The block is delimited by marker comments and rewritten in full on every run;
+the rest of the file is left as it is. Each profile has its own block, and
+with several profiles (--profile a,b or --all-profiles) aliases include the
+profile name. Resources whose names give the same alias, such as "DB Prod"
+and "db-prod", get the start of their ID appended. Run it again after
+starting or stopping sessions, or from an on_session_start / on_session_end
+hook, to keep the entries current.
+
+Entries set HostKeyAlias to the alias, so a resource keeps its known_hosts
+entry when its tunnel port changes.`,
+	Example: `  eg ssh-config
+  eg ssh-config --user ubuntu --print
+  eg ssh-config --remove`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := sshConfigFile
+		if path == "" && !sshConfigPrint {
+			p, err := sshconfig.DefaultPath()
+			if err != nil {
+				return err
+			}
+			path = p
+		}
+		multi := isMultiProfile()
+
+		if sshConfigRemove {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			names, err := targetProfiles(cfg)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				changed, err := sshconfig.UpdateFile(path, name, "")
+				if err != nil {
+					return err
+				}
+				if changed {
+					output.Success("Removed the entries for profile %q from %s", name, path)
+				} else {
+					output.Info("No entries for profile %q in %s", name, path)
+				}
+			}
+			return nil
+		}
+
+		results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+			if err := checkTunnelHost(profile); err != nil {
+				return nil, err
+			}
+			sessions, err := listSessionsFor(name, client)
+			if err != nil {
+				return nil, err
+			}
+			prefix := sshPrefix
+			if multi {
+				prefix += sshconfig.Alias("", name) + "-"
+			}
+			return activeTunnels(profile, sessions, prefix, sshConfigUser, time.Now()), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var blocks []string
+		for i, r := range results {
+			if r.Err != nil {
+				continue
+			}
+			tunnels := r.Value.([]sshTunnel)
+			block, err := sshconfig.Block(r.Profile, sshHosts(tunnels))
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			blocks = append(blocks, block)
+			if sshConfigPrint {
+				continue
+			}
+			if len(tunnels) == 0 {
+				block = "" // drop stale entries rather than keep an empty block
+			}
+			changed, err := sshconfig.UpdateFile(path, r.Profile, block)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			if output.Format == "json" || multi {
+				continue
+			}
+			switch {
+			case len(tunnels) == 0 && changed:
+				output.Success("No active tunnel resources; removed the old entries from %s", path)
+			case len(tunnels) == 0:
+				output.Info("No active tunnel resources; nothing to write to %s", path)
+			case changed:
+				output.Success("Wrote %d host(s) to %s", len(tunnels), path)
+			default:
+				output.Info("%s is up to date", path)
+			}
+		}
+
+		if sshConfigPrint && output.Format != "json" {
+			fmt.Print(strings.Join(blocks, "\n"))
+			for _, r := range results {
+				if r.Err != nil && !multi {
+					return r.Err
+				} else if r.Err != nil {
+					return fmt.Errorf("profile %s: %w", r.Profile, r.Err)
+				}
+			}
+			return nil
+		}
+		return printProfileResults(results, func(v any) {
+			for _, t := range v.([]sshTunnel) {
+				fmt.Printf("  %-24s %s:%d  (%s)\n", t.Alias, t.HostName, t.Port, t.Resource)
+			}
+		})
+	},
+}
+
+var sshCmd = &cobra.Command{
+	Use:   "ssh [user@]<resource> [ssh arguments...]",
+	Short: "SSH to a tunnel resource, starting a session if needed",
+	Long: `Connect to a tunnel resource with ssh. If none of your active sessions covers
+the resource, a session limited to it is started first and eg waits for the
+tunnel to come up.
+
+The resource is given by name or ID; for tunnels that are already up, the
+ssh-config alias works too. Everything after it is passed to ssh: options
+first, then an optional remote command. eg's own flags go before the
+resource:
+
+  eg ssh --reason "deploy" ubuntu@bastion -L 8080:localhost:80 uptime
+
+On Unix eg replaces itself with ssh; on Windows it runs ssh and exits with
+its status.`,
+	Example: `  eg ssh bastion
+  eg ssh -l ubuntu bastion uptime
+  eg --profile prod ssh ops@db-jump -- -A`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: firstArgOnly(completeResourceNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, extra := args[0], args[1:]
+		if len(extra) > 0 && extra[0] == "--" {
+			extra = extra[1:]
+		}
+		user := sshLogin
+		if at := strings.LastIndex(target, "@"); at >= 0 {
+			user, target = target[:at], target[at+1:]
+		}
+		if target == "" {
+			return fmt.Errorf("missing resource name")
+		}
+
+		sshPath, err := exec.LookPath("ssh")
+		if err != nil {
+			return fmt.Errorf("ssh not found in PATH: %w", err)
+		}
+
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		if err := checkTunnelHost(profile); err != nil {
+			return err
+		}
+		name := resolveProfileName()
+		client, err := newClient(name, profile)
+		if err != nil {
+			return err
+		}
+
+		sessions, err := listSessionsFor(name, client)
+		if err != nil {
+			return err
+		}
+		t := findTunnel(activeTunnels(profile, sessions, sshPrefix, user, time.Now()), target)
+		if t == nil {
+			res, err := tunnelResource(client, profile, sessions, target)
+			if err != nil {
+				return err
+			}
+			s, err := startTunnelSession(name, profile, client, res)
+			if err != nil {
+				return err
+			}
+			if t, err = waitForTunnel(client, profile, s, res, user); err != nil {
+				return err
+			}
+		}
+
+		opts, command := splitSSHArgs(extra)
+		sshArgs := []string{"ssh", "-p", strconv.Itoa(t.Port), "-o", "HostKeyAlias=" + t.Alias}
+		if t.User != "" {
+			sshArgs = append(sshArgs, "-l", t.User)
+		}
+		sshArgs = append(sshArgs, opts...)
+		// "--" ends ssh's options, so the host can never be read as one.
+		sshArgs = append(sshArgs, "--", t.HostName)
+		sshArgs = append(sshArgs, command...)
+		return execProgram(sshPath, sshArgs)
+	},
+}
+
+// sshValueOptions are the ssh options that take a value.
+const sshValueOptions = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// splitSSHArgs splits the arguments after the resource into ssh options and
+// the remote command, the way ssh's own parser does: options come first, and
+// the first other argument (or "--") starts the command.
+func splitSSHArgs(args []string) (opts, command []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return opts, args[i+1:]
+		}
+		if len(a) < 2 || a[0] != '-' {
+			return opts, args[i:]
+		}
+		opts = append(opts, a)
+		// In a cluster like "-AL", the first option that takes a value ends
+		// it; the value is the rest of the argument, or the next argument
+		// when nothing is left.
+		for j := 1; j < len(a); j++ {
+			if strings.IndexByte(sshValueOptions, a[j]) < 0 {
+				continue
+			}
+			if j == len(a)-1 && i+1 < len(args) {
+				i++
+				opts = append(opts, args[i])
+			}
+			break
+		}
+	}
+	return opts, nil
+}
+
+// activeTunnels returns the tunnel resources of the active sessions that
+// have an applied rule, one per resource, sorted by alias. When several
+// sessions cover a resource, the one that expires last wins. Resources whose
+// names would share an alias get their short ID appended to it.
+func activeTunnels(profile *config.Profile, sessions []api.Session, prefix, user string, now time.Time) []sshTunnel {
+	var out []sshTunnel
+	remaining := make(map[string]time.Duration)
+	index := make(map[string]int)
+	for i := range sessions {
+		s := &sessions[i]
+		if !s.IsActive() {
+			continue
+		}
+		var applied []api.SessionResourceIp
+		for _, r := range s.ResourceIps {
+			if r.Status == "APPLIED" {
+				applied = append(applied, r)
+			}
+		}
+		left, _ := s.Remaining(now)
+		for _, ti := range getTunnelResources(profile, applied) {
+			t := sshTunnel{
+				Resource:   ti.Resource,
+				ResourceID: ti.ResourceID,
+				HostName:   ti.Host,
+				Port:       ti.Port,
+				User:       user,
+				SessionID:  s.ID,
+				ExpiresAt:  s.ExpiresAt,
+			}
+			if j, ok := index[t.ResourceID]; ok {
+				if left > remaining[t.ResourceID] {
+					out[j], remaining[t.ResourceID] = t, left
+				}
+				continue
+			}
+			index[t.ResourceID], remaining[t.ResourceID] = len(out), left
+			out = append(out, t)
+		}
+	}
+
+	names := make(map[string]string, len(out))
+	for _, t := range out {
+		names[t.ResourceID] = t.Resource
+	}
+	aliases := sshconfig.Aliases(prefix, names)
+	for i := range out {
+		out[i].Alias = aliases[out[i].ResourceID]
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Alias < out[j].Alias })
+	return out
+}
+
+// findTunnel looks a tunnel up by resource ID, name or alias.
+func findTunnel(tunnels []sshTunnel, input string) *sshTunnel {
+	for i, t := range tunnels {
+		if t.ResourceID == input || strings.EqualFold(t.Resource, input) || t.Alias == input {
+			return &tunnels[i]
+		}
+	}
+	return nil
+}
+
+func sshHosts(tunnels []sshTunnel) []sshconfig.Host {
+	hosts := make([]sshconfig.Host, 0, len(tunnels))
+	for _, t := range tunnels {
+		hosts = append(hosts, sshconfig.Host{
+			Alias:        t.Alias,
+			HostName:     t.HostName,
+			Port:         t.Port,
+			User:         t.User,
+			HostKeyAlias: t.Alias,
+			Comment:      fmt.Sprintf("%s (session %s, expires %s)", t.Resource, output.ShortID(t.SessionID), output.FormatTime(t.ExpiresAt)),
+		})
+	}
+	return hosts
+}
+
+// tunnelResource resolves input to the single resource to start a session
+// on. It fails early for resources an active session already covers
+// without a usable tunnel, rather than starting a session that cannot help.
+func tunnelResource(client *api.Client, profile *config.Profile, sessions []api.Session, input string) (*api.Resource, error) {
+	resources, err := resolveResources(client, []string{input}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) != 1 {
+		return nil, fmt.Errorf("'%s' matches %d resources: %s", input, len(resources), strings.Join(resourceNames(resources), ", "))
+	}
+	res := &resources[0]
+	for _, s := range sessions {
+		if !s.IsActive() {
+			continue
+		}
+		for _, r := range s.ResourceIps {
+			if r.ResourceID != res.ID || r.Status != "APPLIED" {
+				continue
+			}
+			if err := unusableTunnel(profile, res, &r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// unusableTunnel explains why an applied rule on res gives nothing to
+// connect to, or returns nil if it does.
+func unusableTunnel(profile *config.Profile, res *api.Resource, r *api.SessionResourceIp) error {
+	if !r.IsTunnel() {
+		return fmt.Errorf("%s is not a tunnel resource; connect to it directly", res.Name)
+	}
+	if _, err := r.Tunnel(); err != nil {
+		return err
+	}
+	if _, err := tunnelHost(profile, r); err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", res.Name, err)
+	}
+	return nil
+}
+
+// startTunnelSession starts a session limited to res, like eg session start
+// --resource.
+func startTunnelSession(name string, profile *config.Profile, client *api.Client, res *api.Resource) (*api.Session, error) {
+	reason := strings.TrimSpace(sshReason)
+	if err := profile.CheckReason(reason); err != nil {
+		return nil, err
+	}
+
+	req := api.StartSessionRequest{
+		Reason:      reason,
+		TicketRef:   strings.TrimSpace(sshTicket),
+		ResourceIDs: []string{res.ID},
+	}
+	length, err := sessionLength(sshDuration, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 {
+		hours, minutes := duration.Split(length)
+		if hours > 0 {
+			req.DurationHours = &hours
+		} else {
+			req.DurationMinutes = &minutes
+		}
+	}
+	req.Ipv4Address, req.Ipv6Address = detectIPs(context.Background())
+
+	output.Info("No active session covers %s; starting one...", res.Name)
+	s, err := client.StartSession(&req)
+	if err != nil {
+		return nil, err
+	}
+	cacheSessionFor(name, s)
+	runHooks(name, profile, lifecycle.Started, s)
+	output.Success("Session %s started, expires %s", output.ShortID(s.ID), output.FormatTime(s.ExpiresAt))
+	return s, nil
+}
+
+// waitForTunnel polls s until its rule on res is applied and has a tunnel
+// port, or sshWait runs out.
+func waitForTunnel(client *api.Client, profile *config.Profile, s *api.Session, res *api.Resource, user string) (*sshTunnel, error) {
+	deadline := time.Now().Add(sshWait)
+	for {
+		if t := findTunnel(activeTunnels(profile, []api.Session{*s}, sshPrefix, user, time.Now()), res.ID); t != nil {
+			return t, nil
+		}
+		for _, r := range s.ResourceIps {
+			if r.ResourceID != res.ID {
+				continue
+			}
+			switch {
+			case r.Status == "FAILED":
+				return nil, fmt.Errorf("tunnel on %s failed: %s", res.Name, valueOrDash(r.ErrorMessage))
+			case r.Status == "APPLIED":
+				if err := unusableTunnel(profile, res, &r); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !s.IsActive() && s.Status != "PENDING" {
+			return nil, fmt.Errorf("session %s is %s", output.ShortID(s.ID), s.Status)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("session %s has no tunnel to %s after %s. Check: eg session get %s", output.ShortID(s.ID), res.Name, sshWait, s.ID)
+		}
+		time.Sleep(time.Second)
+		updated, err := client.GetSession(s.ID)
+		if err != nil {
+			return nil, err
+		}
+		s = updated
+	}
+}
+
+func init() {
+	sshConfigCmd.Flags().StringVar(&sshConfigFile, "file", "", "SSH config file to update (default ~/.ssh/config)")
+	sshConfigCmd.Flags().BoolVar(&sshConfigPrint, "print", false, "Print the block instead of writing it")
+	sshConfigCmd.Flags().BoolVar(&sshConfigRemove, "remove", false, "Remove the block from the file")
+	sshConfigCmd.Flags().StringVar(&sshConfigUser, "user", "", "Set User in every entry")
+	sshConfigCmd.MarkFlagsMutuallyExclusive("print", "remove")
+
+	sshCmd.Flags().SetInterspersed(false)
+	sshCmd.Flags().StringVarP(&sshLogin, "login", "l", "", "Remote user (same as user@resource)")
+	sshCmd.Flags().StringVar(&sshReason, "reason", "", "Reason for the session, if one has to be started")
+	sshCmd.Flags().StringVar(&sshTicket, "ticket", "", "Ticket reference for the session, if one has to be started")
+	sshCmd.Flags().StringVar(&sshDuration, "duration", "", "Session length if one has to be started, e.g. 1h")
+	sshCmd.Flags().DurationVar(&sshWait, "wait", 30*time.Second, "How long to wait for a new session's tunnel")
+
+	for _, c := range []*cobra.Command{sshConfigCmd, sshCmd} {
+		c.Flags().StringVar(&sshPrefix, "prefix", "eg-", "Prefix of Host aliases")
+	}
+
+	rootCmd.AddCommand(sshConfigCmd)
+	rootCmd.AddCommand(sshCmd)
+}
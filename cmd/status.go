@@ -1,67 +1,441 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/netip"
+	"time"
 
 	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
 	"github.com/entryguard-io/cli/internal/output"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var (
+	statusTimeout        time.Duration
+	statusCheck          bool
+	statusExpiringWithin time.Duration
+)
+
+type statusResult struct {
+	profile  *config.Profile // for resolving tunnel hosts; never serialized
+	User     *api.UserInfo
+	Sessions []api.Session
+	IPv4     string
+	IPv6     string
+	Errors   []error
+}
+
+// statusUpdate reports that one of the concurrent status fetches finished.
+type statusUpdate struct {
+	section string
+	err     error
+}
+
+const (
+	sectionProfile  = "profile"
+	sectionSessions = "sessions"
+	sectionIP       = "ip"
+)
+
+// ipCoverage records whether a detected address is whitelisted by an active session.
+type ipCoverage struct {
+	IP        string `json:"ip"`
+	Version   int    `json:"version"`
+	Covered   bool   `json:"covered"`
+	SessionID string `json:"sessionId,omitempty"`
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show profile info, active sessions, and detected IP",
+	Long: `Show profile info, active sessions, and detected IP.
+
+The three lookups run concurrently under a shared --timeout and each section is
+printed as soon as it is available. Exits 2 if some lookups failed and 1 if all
+of them did.
+
+With --check, only the detected IPs and active sessions are fetched and the exit
+status says whether every detected IP is whitelisted: 0 yes, 1 no, 2 unknown
+(a lookup failed). Suitable for shell prompts and git hooks.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := getClient()
+		if statusTimeout <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
+		if isMultiProfile() {
+			if statusCheck {
+				return fmt.Errorf("--check supports a single profile")
+			}
+			return runStatusMulti(cmd.Context())
+		}
+
+		profile, err := getProfile()
 		if err != nil {
 			return err
 		}
+		client, err := newClient(resolveProfileName(), profile)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), statusTimeout)
+		defer cancel()
 
-		type statusResult struct {
-			User     *api.UserInfo
-			Sessions []api.Session
-			IPv4     string
-			IPv6     string
-			Errors   []error
+		if statusCheck {
+			return runStatusCheck(ctx, client.WithContext(ctx))
 		}
 
-		result := &statusResult{}
+		result := &statusResult{profile: profile}
+		updates, n := fetchStatus(ctx, client.WithContext(ctx), result, true)
 
-		// Fetch all in sequence (simple, avoids goroutine complexity for a CLI)
-		user, err := client.GetMe()
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("profile: %w", err))
+		tableMode := output.Format != "json"
+		arrived := make(map[string]bool)
+		for i := 0; i < n; i++ {
+			u := <-updates
+			arrived[u.section] = true
+			if u.err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", u.section, u.err))
+			}
+			if tableMode {
+				printStatusSection(u.section, result)
+				if (u.section == sectionIP || u.section == sectionSessions) && arrived[sectionIP] && arrived[sectionSessions] {
+					printCoverageSection(result)
+				}
+			}
 		}
-		result.User = user
 
-		sessions, err := client.ListSessions()
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("sessions: %w", err))
+		if tableMode {
+			for _, err := range result.Errors {
+				output.Error("%v", err)
+			}
+		} else {
+			output.PrintJSON(statusJSON(result))
 		}
+
+		return statusExit(len(result.Errors), n)
+	},
+}
+
+// fetchStatus starts the profile, session and IP lookups concurrently and
+// returns the channel their completions arrive on along with how many to
+// expect. Each goroutine fills in its own fields of result before sending, so
+// those fields are safe to read once the matching update is received.
+func fetchStatus(ctx context.Context, client *api.Client, result *statusResult, withProfile bool) (<-chan statusUpdate, int) {
+	n := 2
+	if withProfile {
+		n = 3
+	}
+	updates := make(chan statusUpdate, n)
+
+	if withProfile {
+		go func() {
+			user, err := client.GetMe()
+			result.User = user
+			updates <- statusUpdate{section: sectionProfile, err: err}
+		}()
+	}
+
+	go func() {
+		sessions, err := listSessions(client)
 		result.Sessions = sessions
+		updates <- statusUpdate{section: sectionSessions, err: err}
+	}()
 
-		result.IPv4, result.IPv6 = api.DetectIPs()
+	go func() {
+		result.IPv4, result.IPv6 = client.WithContext(ctx).DetectPublicIPs()
+		var err error
+		if result.IPv4 == "" && result.IPv6 == "" {
+			err = errors.New("detection failed")
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+		}
+		updates <- statusUpdate{section: sectionIP, err: err}
+	}()
 
-		if output.Format == "json" {
-			ips := map[string]string{}
-			if result.IPv4 != "" {
-				ips["ipv4"] = result.IPv4
+	return updates, n
+}
+
+// runStatusMulti shows status for several profiles. IP detection runs once
+// and is shared; each profile's lookups run concurrently under one deadline.
+func runStatusMulti(parent context.Context) error {
+	ctx, cancel := context.WithTimeout(parent, statusTimeout)
+	defer cancel()
+
+	var ipv4, ipv6 string
+	ipDone := make(chan struct{})
+	go func() {
+		ipv4, ipv6 = detectIPs(ctx)
+		close(ipDone)
+	}()
+
+	results, err := runForProfiles(func(name string, profile *config.Profile, client *api.Client) (any, error) {
+		result := &statusResult{profile: profile}
+		updates, n := fetchStatusAPI(client.WithContext(ctx), name, result)
+		for i := 0; i < n; i++ {
+			if u := <-updates; u.err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", u.section, u.err))
 			}
-			if result.IPv6 != "" {
-				ips["ipv6"] = result.IPv6
+		}
+		return result, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	<-ipDone
+	var ipErr error
+	if ipv4 == "" && ipv6 == "" {
+		ipErr = fmt.Errorf("%s: detection failed", sectionIP)
+	}
+
+	failed := 0
+	for i := range results {
+		if results[i].Err != nil {
+			failed++
+			continue
+		}
+		res := results[i].Value.(*statusResult)
+		if len(res.Errors) > 0 {
+			failed++
+		}
+		res.IPv4, res.IPv6 = ipv4, ipv6
+		if output.Format == "json" {
+			if ipErr != nil {
+				res.Errors = append(res.Errors, ipErr)
 			}
-			output.PrintJSON(map[string]any{
-				"user":     result.User,
-				"sessions": result.Sessions,
-				"ip":       ips,
-			})
-			return nil
+			results[i].Value = statusJSON(res)
 		}
+	}
 
+	if output.Format != "json" {
 		bold := color.New(color.Bold).SprintFunc()
+		fmt.Println(bold("Detected IP"))
+		printStatusIPs(ipv4, ipv6)
+		if ipErr != nil {
+			output.Error("%v", ipErr)
+		}
+		fmt.Println()
+	}
+
+	err = printProfileResults(results, func(v any) {
+		res := v.(*statusResult)
+		printStatusSection(sectionProfile, res)
+		printStatusSection(sectionSessions, res)
+		printCoverageSection(res)
+		for _, err := range res.Errors {
+			output.Error("%v", err)
+		}
+	})
+	// printProfileResults only counts profiles whose client could not be
+	// built; failed also covers profiles whose lookups failed.
+	var exitErr *exitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+	if ipErr != nil && failed == 0 {
+		return &exitError{code: 2}
+	}
+	return statusExit(failed, len(results))
+}
 
-		// Profile
+// fetchStatusAPI runs the profile and session lookups for one profile of a
+// multi-profile status; IP detection is shared and done by the caller.
+func fetchStatusAPI(client *api.Client, profile string, result *statusResult) (<-chan statusUpdate, int) {
+	updates := make(chan statusUpdate, 2)
+
+	go func() {
+		user, err := client.GetMe()
+		result.User = user
+		updates <- statusUpdate{section: sectionProfile, err: err}
+	}()
+
+	go func() {
+		sessions, err := listSessionsFor(profile, client)
+		result.Sessions = sessions
+		updates <- statusUpdate{section: sectionSessions, err: err}
+	}()
+
+	return updates, 2
+}
+
+// runStatusCheck implements --check: it only answers whether every detected
+// IP is currently whitelisted, reporting through the exit status.
+func runStatusCheck(ctx context.Context, client *api.Client) error {
+	result := &statusResult{}
+	updates, n := fetchStatus(ctx, client, result, false)
+	for i := 0; i < n; i++ {
+		if u := <-updates; u.err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", u.section, u.err))
+		}
+	}
+
+	coverage := computeCoverage(result)
+	expiring := expiringSessions(result.Sessions, statusExpiringWithin, time.Now())
+	whitelisted := len(result.Errors) == 0 && len(coverage) > 0
+	for _, c := range coverage {
+		if !c.Covered {
+			whitelisted = false
+		}
+	}
+
+	if output.Format == "json" {
+		errs := make([]string, 0, len(result.Errors))
+		for _, err := range result.Errors {
+			errs = append(errs, err.Error())
+		}
+		output.PrintJSON(map[string]any{
+			"whitelisted": whitelisted,
+			"coverage":    coverage,
+			"expiring":    sessionIDs(expiring),
+			"errors":      errs,
+		})
+	} else {
+		for _, err := range result.Errors {
+			output.Error("%v", err)
+		}
+		for _, c := range coverage {
+			if !c.Covered {
+				output.Error("IPv%d %s is not whitelisted by any active session", c.Version, c.IP)
+			}
+		}
+		if whitelisted {
+			output.Success("Whitelisted")
+		}
+		for _, s := range expiring {
+			output.Info("Session %s expires in %s — run: eg session extend %s --for 1h",
+				output.ShortID(s.ID), output.FormatDuration(s.ExpiresAt), output.ShortID(s.ID))
+		}
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		return &exitError{code: 2}
+	case !whitelisted:
+		return &exitError{code: 1}
+	default:
+		return nil
+	}
+}
+
+// computeCoverage checks each detected IP against the active sessions.
+func computeCoverage(result *statusResult) []ipCoverage {
+	var coverage []ipCoverage
+	for _, ip := range []string{result.IPv4, result.IPv6} {
+		if ip == "" {
+			continue
+		}
+		c := ipCoverage{IP: ip, Version: 4}
+		if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() && !addr.Is4In6() {
+			c.Version = 6
+		}
+		if s := api.CoveringSession(result.Sessions, ip); s != nil {
+			c.Covered = true
+			c.SessionID = s.ID
+		}
+		coverage = append(coverage, c)
+	}
+	return coverage
+}
+
+// expiringSessions returns active sessions that expire within the threshold.
+func expiringSessions(sessions []api.Session, within time.Duration, now time.Time) []api.Session {
+	var expiring []api.Session
+	for _, s := range sessions {
+		if !s.IsActive() {
+			continue
+		}
+		if d, ok := s.Remaining(now); ok && d > 0 && d <= within {
+			expiring = append(expiring, s)
+		}
+	}
+	return expiring
+}
+
+func sessionIDs(sessions []api.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// statusExit maps the number of failed lookups to the command's exit status.
+func statusExit(failed, total int) error {
+	switch {
+	case failed == 0:
+		return nil
+	case failed == total:
+		return &exitError{code: 1}
+	default:
+		return &exitError{code: 2}
+	}
+}
+
+func statusJSON(result *statusResult) map[string]any {
+	ips := map[string]string{}
+	if result.IPv4 != "" {
+		ips["ipv4"] = result.IPv4
+	}
+	if result.IPv6 != "" {
+		ips["ipv6"] = result.IPv6
+	}
+	errs := make([]string, 0, len(result.Errors))
+	for _, err := range result.Errors {
+		errs = append(errs, err.Error())
+	}
+	return map[string]any{
+		"user":     result.User,
+		"sessions": viewSessions(result.Sessions, result.profile),
+		"ip":       ips,
+		"coverage": computeCoverage(result),
+		"expiring": sessionIDs(expiringSessions(result.Sessions, statusExpiringWithin, time.Now())),
+		"errors":   errs,
+	}
+}
+
+// printCoverageSection compares detected IPs with active sessions and flags
+// addresses that are not whitelisted and sessions about to expire.
+func printCoverageSection(result *statusResult) {
+	if result.IPv4 == "" && result.IPv6 == "" {
+		return
+	}
+	bold := color.New(color.Bold).SprintFunc()
+	fmt.Println(bold("Coverage"))
+	for _, c := range computeCoverage(result) {
+		if c.Covered {
+			fmt.Printf("  %s IPv%d %s whitelisted by session %s\n", color.GreenString("✓"), c.Version, c.IP, output.ShortID(c.SessionID))
+		} else {
+			fmt.Printf("  %s IPv%d %s %s\n", color.RedString("✗"), c.Version, c.IP, color.RedString("not whitelisted by any active session"))
+		}
+	}
+	for _, s := range expiringSessions(result.Sessions, statusExpiringWithin, time.Now()) {
+		fmt.Printf("  %s session %s expires in %s — run: eg session extend %s --for 1h\n",
+			color.YellowString("!"), output.ShortID(s.ID), output.FormatDuration(s.ExpiresAt), output.ShortID(s.ID))
+	}
+	fmt.Println()
+}
+
+func printStatusIPs(ipv4, ipv6 string) {
+	if ipv4 != "" {
+		fmt.Printf("  IPv4: %s\n", ipv4)
+	}
+	if ipv6 != "" {
+		fmt.Printf("  IPv6: %s\n", ipv6)
+	}
+	if ipv4 == "" && ipv6 == "" {
+		fmt.Println("  (unavailable)")
+	}
+}
+
+func printStatusSection(section string, result *statusResult) {
+	bold := color.New(color.Bold).SprintFunc()
+
+	switch section {
+	case sectionProfile:
 		fmt.Println(bold("Profile"))
 		if result.User != nil {
 			fmt.Printf("  Organization: %s\n", result.User.OrganizationName)
@@ -75,22 +449,12 @@ var statusCmd = &cobra.Command{
 		} else {
 			fmt.Println("  (unavailable)")
 		}
-		fmt.Println()
 
-		// IP
+	case sectionIP:
 		fmt.Println(bold("Detected IP"))
-		if result.IPv4 != "" {
-			fmt.Printf("  IPv4: %s\n", result.IPv4)
-		}
-		if result.IPv6 != "" {
-			fmt.Printf("  IPv6: %s\n", result.IPv6)
-		}
-		if result.IPv4 == "" && result.IPv6 == "" {
-			fmt.Println("  (unavailable)")
-		}
-		fmt.Println()
+		printStatusIPs(result.IPv4, result.IPv6)
 
-		// Active Sessions
+	case sectionSessions:
 		fmt.Println(bold("Active Sessions"))
 		var active []api.Session
 		for _, s := range result.Sessions {
@@ -109,7 +473,7 @@ var statusCmd = &cobra.Command{
 					ip = s.Ipv6Address
 				}
 				rows = append(rows, []string{
-					s.ID[:8],
+					output.ShortID(s.ID),
 					output.StatusColor(s.Status),
 					ip,
 					output.FormatDuration(s.ExpiresAt),
@@ -117,11 +481,13 @@ var statusCmd = &cobra.Command{
 			}
 			output.PrintTable([]string{"ID", "STATUS", "IP", "REMAINING"}, rows)
 		}
-
-		return nil
-	},
+	}
+	fmt.Println()
 }
 
 func init() {
+	statusCmd.Flags().DurationVar(&statusTimeout, "timeout", 10*time.Second, "Deadline shared by all status lookups")
+	statusCmd.Flags().BoolVar(&statusCheck, "check", false, "Only check whether the current IP is whitelisted (exit 0 yes, 1 no, 2 unknown)")
+	statusCmd.Flags().DurationVar(&statusExpiringWithin, "expiring-within", 15*time.Minute, "Flag active sessions expiring within this window")
 	rootCmd.AddCommand(statusCmd)
 }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/cache"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/duration"
+	"github.com/entryguard-io/cli/internal/hooks"
+	"github.com/entryguard-io/cli/internal/lifecycle"
+	"github.com/entryguard-io/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval       time.Duration
+	watchExpiringBefore string
+)
+
+var sessionWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch your sessions and run lifecycle hooks",
+	Long: `Poll your sessions and report when one starts, is extended, is about to
+expire or ends, running the profile's hooks for each event. This also
+catches sessions started or stopped elsewhere, e.g. in the web app, and
+sessions that simply expire.
+
+Hooks live in the profile's hooks table in ~/.entryguard/config.toml:
+
+  [profiles.prod.hooks]
+  on_session_start    = ["~/bin/update-ssh-config"]
+  on_session_expiring = ["notify-send 'EntryGuard session expiring'"]
+  on_session_end      = ["http://127.0.0.1:9000/eg"]
+  expiring_before     = "15m"
+
+URLs receive a JSON POST of {"event", "profile", "session"}; commands get
+the same JSON on stdin and EG_EVENT, EG_PROFILE, EG_SESSION_ID,
+EG_SESSION_STATUS, EG_SESSION_EXPIRES_AT, EG_SESSION_IPV4 and
+EG_SESSION_IPV6 in the environment. on_session_start also runs when a
+session is extended. session start, stop and extend run the same hooks;
+watch does not run them again for events those commands already handled.
+
+With -o json, events are written as JSON lines.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		profile, err := getProfile()
+		if err != nil {
+			return err
+		}
+		name := resolveProfileName()
+		client, err := newClient(name, profile)
+		if err != nil {
+			return err
+		}
+
+		before, err := profile.Hooks.Expiring()
+		if err != nil {
+			return err
+		}
+		if watchExpiringBefore != "" {
+			if before, err = duration.Parse(watchExpiringBefore); err != nil {
+				return fmt.Errorf("invalid --expiring-before: %w", err)
+			}
+		}
+
+		tracker := lifecycle.NewTracker(before)
+		enc := json.NewEncoder(os.Stdout)
+		poll := func() {
+			sessions, err := listSessionsFor(name, client)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "poll failed: %v\n", err)
+				return
+			}
+			for _, c := range tracker.Update(sessions, time.Now()) {
+				s := c.Session
+				if c.Event == lifecycle.Ended {
+					s = finalSession(name, client, s)
+				}
+				if output.Format == "json" {
+					enc.Encode(hooks.Payload{Event: c.Event, Profile: name, Session: &s})
+				} else {
+					printWatchEvent(c.Event, &s)
+				}
+				runHooks(name, profile, c.Event, &s)
+			}
+		}
+
+		if output.Format != "json" {
+			output.Info("Watching sessions for profile %q every %s (Ctrl-C to stop)", name, watchInterval)
+		}
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			poll()
+			select {
+			case <-ticker.C:
+			case <-sigCh:
+				return nil
+			}
+		}
+	},
+}
+
+// finalSession returns s as it ended. Sessions that simply dropped off the
+// active list are fetched to learn how they ended.
+func finalSession(name string, client *api.Client, s api.Session) api.Session {
+	if !s.IsActive() && s.Status != "PENDING" {
+		return s
+	}
+	final, err := client.GetSession(s.ID)
+	if err != nil {
+		return s
+	}
+	cacheSessionFor(name, final)
+	return *final
+}
+
+func printWatchEvent(event lifecycle.Event, s *api.Session) {
+	var detail string
+	switch event {
+	case lifecycle.Started:
+		detail = fmt.Sprintf("started, expires %s (%s)", output.FormatTime(s.ExpiresAt), output.FormatDuration(s.ExpiresAt))
+	case lifecycle.Extended:
+		detail = fmt.Sprintf("extended, now expires %s (%s)", output.FormatTime(s.ExpiresAt), output.FormatDuration(s.ExpiresAt))
+	case lifecycle.Expiring:
+		detail = fmt.Sprintf("expires in %s. Extend with: eg session extend %s --for 1h", output.FormatDuration(s.ExpiresAt), output.ShortID(s.ID))
+	case lifecycle.Ended:
+		detail = "ended: " + output.StatusColor(s.Status)
+		if s.EndedReason != "" {
+			detail += " (" + s.EndedReason + ")"
+		}
+	}
+	fmt.Printf("%s  %s  %s  %s\n", time.Now().Format("15:04:05"), output.ShortID(s.ID), sessionIPs(s), detail)
+}
+
+// runHooks runs the profile's hooks for a session event, unless another eg
+// command already ran them for the same event. Hook failures are reported
+// on stderr and never fail the command.
+func runHooks(name string, profile *config.Profile, event lifecycle.Event, s *api.Session) {
+	list := hooks.For(&profile.Hooks, event)
+	if len(list) == 0 {
+		return
+	}
+	// URL hooks go through the profile's ca_file, client certificate and
+	// proxy, like its API requests.
+	transport, err := transportConfig(profile).Transport()
+	if err != nil {
+		output.Error("hooks for profile %q: %v", name, err)
+		return
+	}
+	client := &http.Client{Transport: transport, Timeout: hooks.Timeout}
+	// A session starts and ends once; extensions and expiry warnings repeat
+	// with each new expiry time.
+	expiresAt := ""
+	if event == lifecycle.Extended || event == lifecycle.Expiring {
+		expiresAt = s.ExpiresAt
+	}
+	if fired, _ := cache.MarkHookFired(name, s.ID, string(event), expiresAt); fired {
+		return
+	}
+	if err := hooks.Run(context.Background(), client, list, hooks.Payload{Event: event, Profile: name, Session: s}); err != nil {
+		output.Error("%v", err)
+	}
+}
+
+func init() {
+	sessionWatchCmd.Flags().DurationVar(&watchInterval, "interval", 30*time.Second, "How often to poll")
+	sessionWatchCmd.Flags().StringVar(&watchExpiringBefore, "expiring-before", "", "Lead time for expiring events (default: the profile's hooks.expiring_before, else 10m)")
+	sessionCmd.AddCommand(sessionWatchCmd)
+}
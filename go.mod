@@ -8,6 +8,7 @@ require (
 	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/rodaine/table v1.3.0
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/sys v0.28.0
 	golang.org/x/term v0.27.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -17,5 +18,4 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.28.0 // indirect
 )
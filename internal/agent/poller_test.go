@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+func TestPollerAgainstMockServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	// Scripts append "<command> <cidr>" to a log file.
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "rules.log")
+	for _, kind := range []string{"apply", "revoke"} {
+		os.Mkdir(filepath.Join(dir, kind), 0755)
+		script := "#!/bin/bash\necho \"" + kind + " $1\" >> " + logFile + "\n"
+		os.WriteFile(filepath.Join(dir, kind, "01-log.sh"), []byte(script), 0755)
+	}
+
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	srv.AddResource(entryguard.Resource{Name: "fw", ResourceType: "AGENT", ScriptDir: dir, Enabled: true})
+
+	client := NewClient(srv.URL, entryguardtest.APIKey)
+	if _, err := client.Register(RegisterRequest{Name: "test-agent", AgentVersion: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	poller := NewPoller(client, NewExecutor("/bin/bash", 10*time.Second), time.Second)
+
+	ctx := context.Background()
+	api := srv.Client()
+	session, err := api.StartSession(ctx, &entryguard.StartSessionRequest{Ipv4Address: "203.0.113.7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Status != "PENDING" {
+		t.Fatalf("status before agent poll = %s, want PENDING", session.Status)
+	}
+
+	poller.poll()
+	session, err = api.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := session.ResourceIps[0]
+	if session.Status != "ACTIVE" || rule.Status != "APPLIED" || !strings.HasPrefix(rule.ProviderRuleId, "agent-") {
+		t.Fatalf("after apply: session %s, rule %s (%s)", session.Status, rule.Status, rule.ProviderRuleId)
+	}
+
+	if _, err := api.StopSession(ctx, session.ID); err != nil {
+		t.Fatal(err)
+	}
+	poller.poll()
+	session, err = api.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule := session.ResourceIps[0]; rule.Status != "REMOVED" {
+		t.Errorf("after revoke: rule %s, want REMOVED", rule.Status)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "apply 203.0.113.7/32\nrevoke 203.0.113.7/32\n"; string(data) != want {
+		t.Errorf("scripts ran:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestPollerRevokesExpiredSession(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "rules.log")
+	for _, kind := range []string{"apply", "revoke"} {
+		os.Mkdir(filepath.Join(dir, kind), 0755)
+		script := "#!/bin/bash\necho \"" + kind + " $1\" >> " + logFile + "\n"
+		os.WriteFile(filepath.Join(dir, kind, "01-log.sh"), []byte(script), 0755)
+	}
+
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	now := time.Now()
+	srv.Now = func() time.Time { return now }
+	srv.AddResource(entryguard.Resource{Name: "fw", ResourceType: "AGENT", ScriptDir: dir, Enabled: true})
+
+	client := NewClient(srv.URL, entryguardtest.APIKey)
+	if _, err := client.Register(RegisterRequest{Name: "test-agent", AgentVersion: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	poller := NewPoller(client, NewExecutor("/bin/bash", 10*time.Second), time.Second)
+
+	ctx := context.Background()
+	api := srv.Client()
+	minutes := 30
+	session, err := api.StartSession(ctx, &entryguard.StartSessionRequest{Ipv4Address: "203.0.113.7", DurationMinutes: &minutes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	poller.poll()
+
+	// Expiry is noticed on the next read, which queues the REVOKE.
+	now = now.Add(time.Hour)
+	srv.Sessions()
+	var revoke *entryguardtest.AgentCommand
+	for _, c := range srv.Commands() {
+		if c.CommandType == "REVOKE" && c.SessionID == session.ID {
+			revoke = &c
+		}
+	}
+	if revoke == nil || revoke.Status != entryguardtest.CommandQueued {
+		t.Fatalf("after expiry: revoke command %+v, want one queued", revoke)
+	}
+
+	poller.poll()
+	session, err = api.GetSession(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Status != "EXPIRED" || session.ResourceIps[0].Status != "REMOVED" {
+		t.Errorf("after revoke: session %s, rule %s; want EXPIRED, REMOVED", session.Status, session.ResourceIps[0].Status)
+	}
+	for _, c := range srv.Commands() {
+		if c.ID == revoke.ID && c.Status != entryguardtest.CommandSucceeded {
+			t.Errorf("revoke command status = %s, want %s", c.Status, entryguardtest.CommandSucceeded)
+		}
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "apply 203.0.113.7/32\nrevoke 203.0.113.7/32\n"; string(data) != want {
+		t.Errorf("scripts ran:\n%s\nwant:\n%s", data, want)
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastHeartbeat returns when the agent last checked in, or false if it never
+// has (or the server sent an unparseable timestamp).
+func (a *AgentResponse) LastHeartbeat() (time.Time, bool) {
+	if a.LastHeartbeatAt == nil || *a.LastHeartbeatAt == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, *a.LastHeartbeatAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// IsStale reports whether the agent has not heartbeated within staleAfter.
+// Agents that never heartbeated are stale.
+func (a *AgentResponse) IsStale(now time.Time, staleAfter time.Duration) bool {
+	t, ok := a.LastHeartbeat()
+	return !ok || now.Sub(t) > staleAfter
+}
+
+// Version returns the reported agent version, or "" if unknown.
+func (a *AgentResponse) Version() string {
+	if a.AgentVersion == nil {
+		return ""
+	}
+	return *a.AgentVersion
+}
+
+// CompareVersions compares two dotted versions such as "v1.4.2" or
+// "1.10.0-rc1" numerically, returning -1, 0 or 1. A leading "v" is ignored,
+// missing components count as zero, and a pre-release suffix sorts before
+// the release it precedes. Versions that don't parse (e.g. "dev") sort
+// before everything else.
+func CompareVersions(a, b string) int {
+	pa, preA, okA := parseVersion(a)
+	pb, preB, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+// IsVersion reports whether s is a version CompareVersions can order
+// numerically, as opposed to one that merely sorts first.
+func IsVersion(s string) bool {
+	_, _, ok := parseVersion(s)
+	return ok
+}
+
+func parseVersion(s string) ([]int, string, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var pre string
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		s, pre = s[:i], s[i+1:]
+	}
+	if s == "" {
+		return nil, "", false
+	}
+	var parts []int
+	for _, p := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre, true
+}
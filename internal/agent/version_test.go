@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.3.0-rc1", "1.3.0", -1},
+		{"1.3.0", "1.3.0-rc1", 1},
+		{"1.3.0-rc1", "1.3.0-rc2", -1},
+		{"1.3.0+build5", "1.3.0", 0},
+		{"dev", "0.0.1", -1},
+		{"0.0.1", "dev", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsVersion(t *testing.T) {
+	for _, v := range []string{"1.4.0", "v1.4", "1.3.0-rc1", "2"} {
+		if !IsVersion(v) {
+			t.Errorf("IsVersion(%q) = false", v)
+		}
+	}
+	for _, v := range []string{"", "latest", "dev", "1.x", "v", "1..2"} {
+		if IsVersion(v) {
+			t.Errorf("IsVersion(%q) = true", v)
+		}
+	}
+}
+
+func TestAgentIsStale(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ts := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		hb   *string
+		want bool
+	}{
+		{"recent", ts("2026-10-18T11:59:30Z"), false},
+		{"old", ts("2026-10-18T11:50:00Z"), true},
+		{"never", nil, true},
+		{"garbage", ts("yesterday"), true},
+	}
+	for _, tt := range tests {
+		a := &AgentResponse{LastHeartbeatAt: tt.hb}
+		if got := a.IsStale(now, 2*time.Minute); got != tt.want {
+			t.Errorf("%s: IsStale = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
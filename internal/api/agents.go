@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/entryguard-io/cli/internal/agent"
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// ListAgents returns the eg-agent instances registered in the organization.
+// Requires org admin rights.
+func (c *Client) ListAgents() ([]agent.AgentResponse, error) {
+	agents, err := c.sdk().ListAgents(c.context())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]agent.AgentResponse, len(agents))
+	for i := range agents {
+		out[i] = agentResponse(&agents[i])
+	}
+	return out, nil
+}
+
+func (c *Client) GetAgent(id string) (*agent.AgentResponse, error) {
+	a, err := c.sdk().GetAgent(c.context(), id)
+	if err != nil {
+		return nil, err
+	}
+	r := agentResponse(a)
+	return &r, nil
+}
+
+// DeleteAgent deregisters an agent. Its API key stops working immediately.
+func (c *Client) DeleteAgent(id string) error {
+	return c.sdk().DeleteAgent(c.context(), id)
+}
+
+// agentResponse converts the SDK type to the one shared with eg-agent, where
+// unreported fields are nil.
+func agentResponse(a *entryguard.Agent) agent.AgentResponse {
+	return agent.AgentResponse{
+		ID:              a.ID,
+		Name:            a.Name,
+		Status:          a.Status,
+		AgentVersion:    optional(a.AgentVersion),
+		Hostname:        optional(a.Hostname),
+		OsInfo:          optional(a.OsInfo),
+		LastHeartbeatAt: optional(a.LastHeartbeatAt),
+		CreatedAt:       a.CreatedAt,
+	}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
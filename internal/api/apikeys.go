@@ -0,0 +1,38 @@
+package api
+
+import "github.com/entryguard-io/cli/pkg/entryguard"
+
+// Well-known API key scopes.
+const (
+	ScopeAgentConnect = entryguard.ScopeAgentConnect
+)
+
+type (
+	ApiKey              = entryguard.ApiKey
+	CreateApiKeyRequest = entryguard.CreateApiKeyRequest
+	CreatedApiKey       = entryguard.CreatedApiKey
+)
+
+// ListApiKeys returns the current user's API keys.
+func (c *Client) ListApiKeys() ([]ApiKey, error) {
+	return c.sdk().ListApiKeys(c.context())
+}
+
+func (c *Client) CreateApiKey(req *CreateApiKeyRequest) (*CreatedApiKey, error) {
+	return c.sdk().CreateApiKey(c.context(), req)
+}
+
+// RevokeApiKey revokes a key. It stops working immediately.
+func (c *Client) RevokeApiKey(id string) error {
+	return c.sdk().RevokeApiKey(c.context(), id)
+}
+
+// FindApiKey returns the key in keys whose prefix matches secret, or nil.
+func FindApiKey(keys []ApiKey, secret string) *ApiKey {
+	for i := range keys {
+		if keys[i].Matches(secret) {
+			return &keys[i]
+		}
+	}
+	return nil
+}
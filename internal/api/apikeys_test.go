@@ -0,0 +1,21 @@
+package api
+
+import "testing"
+
+func TestFindApiKey(t *testing.T) {
+	keys := []ApiKey{
+		{ID: "1", KeyPrefix: "eg_live_ab12"},
+		{ID: "2", KeyPrefix: "eg_live_cd34"},
+		{ID: "3"},
+	}
+
+	if k := FindApiKey(keys, "eg_live_cd34ffffffff"); k == nil || k.ID != "2" {
+		t.Errorf("FindApiKey = %v, want key 2", k)
+	}
+	if k := FindApiKey(keys, "eg_live_zz99ffffffff"); k != nil {
+		t.Errorf("FindApiKey matched %s for an unknown secret", k.ID)
+	}
+	if k := FindApiKey(keys, ""); k != nil {
+		t.Errorf("FindApiKey matched %s for an empty secret", k.ID)
+	}
+}
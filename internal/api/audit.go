@@ -0,0 +1,75 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+type (
+	AuditEvent = entryguard.AuditEvent
+	AuditPage  = entryguard.AuditPage
+	AuditQuery = entryguard.AuditQuery
+)
+
+// ListAuditEvents returns one page of the audit log, newest first. Requires
+// org admin rights.
+func (c *Client) ListAuditEvents(q AuditQuery) (*AuditPage, error) {
+	return c.sdk().ListAuditEvents(c.context(), q)
+}
+
+// ListAllAuditEvents pages through the audit log until the last page, or
+// until limit events have been collected when limit > 0.
+func (c *Client) ListAllAuditEvents(q AuditQuery, limit int) ([]AuditEvent, error) {
+	return c.sdk().ListAllAuditEvents(c.context(), q, limit)
+}
+
+// AuditCursor tracks the position of a follower polling the audit log. Since
+// is inclusive on the server, so events sharing the newest timestamp come
+// back on the next poll; the cursor remembers their IDs to skip them.
+// Events with a timestamp that doesn't parse can't be placed, so they are
+// never dropped as old: each is returned once, by ID.
+type AuditCursor struct {
+	Since   time.Time
+	seen    map[string]bool
+	undated map[string]bool
+}
+
+// Advance returns the events not yet seen, oldest first, and moves the
+// cursor past them.
+func (c *AuditCursor) Advance(events []AuditEvent) []AuditEvent {
+	sorted := make([]AuditEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time().Before(sorted[j].Time())
+	})
+
+	var fresh []AuditEvent
+	for _, e := range sorted {
+		t := e.Time()
+		if t.IsZero() {
+			if !c.undated[e.ID] {
+				if c.undated == nil {
+					c.undated = make(map[string]bool)
+				}
+				c.undated[e.ID] = true
+				fresh = append(fresh, e)
+			}
+			continue
+		}
+		if t.Before(c.Since) || c.seen[e.ID] {
+			continue
+		}
+		if t.After(c.Since) {
+			c.Since = t
+			c.seen = nil
+		}
+		if c.seen == nil {
+			c.seen = make(map[string]bool)
+		}
+		c.seen[e.ID] = true
+		fresh = append(fresh, e)
+	}
+	return fresh
+}
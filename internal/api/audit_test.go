@@ -0,0 +1,64 @@
+package api
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAuditCursorAdvance(t *testing.T) {
+	ev := func(id, ts string) AuditEvent { return AuditEvent{ID: id, Timestamp: ts} }
+	ids := func(events []AuditEvent) []string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	c := &AuditCursor{Since: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+
+	// Newest-first page as the server returns it; the old event is dropped.
+	got := ids(c.Advance([]AuditEvent{
+		ev("c", "2026-10-18T12:00:02Z"),
+		ev("b", "2026-10-18T12:00:01Z"),
+		ev("a", "2026-10-18T12:00:01Z"),
+		ev("old", "2026-10-18T11:59:00Z"),
+	}))
+	if want := []string{"b", "a", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("first poll = %v, want %v", got, want)
+	}
+	if !c.Since.Equal(time.Date(2026, 10, 18, 12, 0, 2, 0, time.UTC)) {
+		t.Errorf("Since = %v", c.Since)
+	}
+
+	// The next poll repeats "c" (inclusive since) and adds a new event at the
+	// same timestamp and one after it.
+	got = ids(c.Advance([]AuditEvent{
+		ev("e", "2026-10-18T12:00:03Z"),
+		ev("d", "2026-10-18T12:00:02Z"),
+		ev("c", "2026-10-18T12:00:02Z"),
+	}))
+	if want := []string{"d", "e"}; !slices.Equal(got, want) {
+		t.Fatalf("second poll = %v, want %v", got, want)
+	}
+
+	if got := c.Advance(nil); len(got) != 0 {
+		t.Errorf("empty poll returned %v", got)
+	}
+
+	// An event whose timestamp doesn't parse is shown once rather than
+	// dropped as older than the cursor, and doesn't move it.
+	since := c.Since
+	got = ids(c.Advance([]AuditEvent{ev("f", "2026-10-18T12:00:04Z"), ev("bad", "yesterday")}))
+	if want := []string{"bad", "f"}; !slices.Equal(got, want) {
+		t.Fatalf("poll with undated event = %v, want %v", got, want)
+	}
+	got = ids(c.Advance([]AuditEvent{ev("g", "2026-10-18T12:00:05Z"), ev("bad", "yesterday")}))
+	if want := []string{"g"}; !slices.Equal(got, want) {
+		t.Fatalf("repeated undated event: poll = %v, want %v", got, want)
+	}
+	if !c.Since.After(since) {
+		t.Errorf("Since = %v, want past %v", c.Since, since)
+	}
+}
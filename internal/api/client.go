@@ -1,22 +1,31 @@
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
 )
 
 type Client struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
+
+	// Tokens, when set, authenticates requests with an OAuth bearer token
+	// (from `eg login`) instead of APIKey.
+	Tokens TokenSource
+
+	ctx context.Context
 }
 
+// TokenSource supplies OAuth access tokens, refreshing them as needed.
+type TokenSource = entryguard.TokenSource
+
 func NewClient(baseURL, apiKey string) *Client {
 	return &Client{
 		BaseURL: baseURL,
@@ -27,178 +36,110 @@ func NewClient(baseURL, apiKey string) *Client {
 	}
 }
 
-type APIError struct {
-	Message          string            `json:"message"`
-	Status           int               `json:"status"`
-	Error            string            `json:"error"`
-	ValidationErrors map[string]string `json:"validationErrors"`
+// WithContext returns a shallow copy of the client whose requests are bound
+// to ctx, so callers can cancel them or share a deadline.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
 }
 
-func (e *APIError) String() string {
-	if e.Message != "" {
-		return e.Message
-	}
-	if e.Error != "" {
-		return e.Error
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
 	}
-	return fmt.Sprintf("HTTP %d", e.Status)
+	return context.Background()
 }
 
-// Response types
-
-type UserInfo struct {
-	ID               string `json:"id"`
-	Email            string `json:"email"`
-	Name             string `json:"name"`
-	IsOrgAdmin       bool   `json:"isOrgAdmin"`
-	PlatformRole     string `json:"platformRole"`
-	OrganizationID   string `json:"organizationId"`
-	OrganizationName string `json:"organizationName"`
-	OrganizationSlug string `json:"organizationSlug"`
-	SubscriptionTier string `json:"subscriptionTier"`
-	MfaEnabled       bool   `json:"mfaEnabled"`
-}
+// APIError is returned for HTTP error responses.
+type APIError = entryguard.APIError
 
-type IpResponse struct {
-	IP        string `json:"ip"`
-	Version   int    `json:"version"`
-	Timestamp string `json:"timestamp"`
-}
+// UserAgent is sent with every API request.
+var UserAgent = "eg"
 
-type SessionResourceIp struct {
-	ID             string `json:"id"`
-	ResourceID     string `json:"resourceId"`
-	ResourceName   string `json:"resourceName"`
-	IpVersion      int    `json:"ipVersion"`
-	IpAddress      string `json:"ipAddress"`
-	Status         string `json:"status"`
-	ProviderRuleId string `json:"providerRuleId"`
-	AppliedAt      string `json:"appliedAt"`
-	RemovedAt      string `json:"removedAt"`
-	ErrorMessage   string `json:"errorMessage"`
-}
-
-type Session struct {
-	ID               string              `json:"id"`
-	UserID           string              `json:"userId"`
-	UserName         string              `json:"userName"`
-	UserEmail        string              `json:"userEmail"`
-	Ipv4Address      string              `json:"ipv4Address"`
-	Ipv6Address      string              `json:"ipv6Address"`
-	Status           string              `json:"status"`
-	StartedAt        string              `json:"startedAt"`
-	ExpiresAt        string              `json:"expiresAt"`
-	EndedAt          string              `json:"endedAt"`
-	EndedReason      string              `json:"endedReason"`
-	ResourceIps      []SessionResourceIp `json:"resourceIps"`
-	CreatedAt        string              `json:"createdAt"`
-	OrganizationName string              `json:"organizationName"`
-}
+// Response types, shared with the public SDK.
+type (
+	UserInfo             = entryguard.UserInfo
+	IpResponse           = entryguard.IpResponse
+	SessionResourceIp    = entryguard.SessionResourceIp
+	Session              = entryguard.Session
+	SessionPage          = entryguard.SessionPage
+	SessionHistoryQuery  = entryguard.SessionHistoryQuery
+	StartSessionRequest  = entryguard.StartSessionRequest
+	ExtendSessionRequest = entryguard.ExtendSessionRequest
+	Tunnel               = entryguard.Tunnel
+)
 
-type StartSessionRequest struct {
-	DurationHours *int   `json:"durationHours,omitempty"`
-	Ipv4Address   string `json:"ipv4Address,omitempty"`
-	Ipv6Address   string `json:"ipv6Address,omitempty"`
-}
+// DefaultTunnelHost is the edge host for tunnel rules that do not name one.
+const DefaultTunnelHost = entryguard.DefaultTunnelHost
 
-type ExtendSessionRequest struct {
-	AdditionalHours int `json:"additionalHours"`
+// ValidateTunnelHost checks that host is a bare host name or IP address,
+// safe to pass to ssh.
+func ValidateTunnelHost(host string) error {
+	return entryguard.ValidateTunnelHost(host)
 }
 
-// API methods
-
-func (c *Client) do(method, path string, body any) ([]byte, error) {
-	var reqBody io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request: %w", err)
-		}
-		reqBody = bytes.NewReader(data)
-	}
-
-	req, err := http.NewRequest(method, c.BaseURL+path, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	if c.APIKey != "" {
-		req.Header.Set("X-API-Key", c.APIKey)
-	}
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+// sdk returns an SDK client configured from c's fields. It is cheap, so a
+// new one is built per call to pick up changes to the fields.
+func (c *Client) sdk() *entryguard.Client {
+	opts := []entryguard.Option{
+		entryguard.WithBaseURL(c.BaseURL),
+		entryguard.WithHTTPClient(c.HTTPClient),
+		entryguard.WithAPIKey(c.APIKey),
+		entryguard.WithUserAgent(UserAgent),
 	}
-
-	if resp.StatusCode >= 400 {
-		var apiErr APIError
-		if json.Unmarshal(respBody, &apiErr) == nil && (apiErr.Message != "" || apiErr.Error != "") {
-			return nil, fmt.Errorf("%s", apiErr.String())
-		}
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+	if c.Tokens != nil {
+		opts = append(opts, entryguard.WithTokenSource(c.Tokens))
 	}
-
-	return respBody, nil
+	return entryguard.NewClient(opts...)
 }
 
 func (c *Client) GetMe() (*UserInfo, error) {
-	data, err := c.do("GET", "/auth/me", nil)
-	if err != nil {
-		return nil, err
-	}
-	var user UserInfo
-	if err := json.Unmarshal(data, &user); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return &user, nil
+	return c.sdk().GetMe(c.context())
 }
 
+// Public IP detection endpoints. Each returns {"ip": "..."} for the address
+// family it is reachable over.
+const (
+	IPv4DetectURL = "https://api4.ipify.org?format=json"
+	IPv6DetectURL = "https://api6.ipify.org?format=json"
+)
+
 // DetectIPs queries ipify to detect both IPv4 and IPv6 addresses concurrently.
 // Either or both may be returned; errors are silently ignored per IP version.
 func DetectIPs() (ipv4, ipv6 string) {
+	return DetectIPsContext(context.Background())
+}
+
+// DetectIPsContext is DetectIPs bound to ctx; lookups still running when ctx
+// is done are abandoned and their address left empty.
+func DetectIPsContext(ctx context.Context) (ipv4, ipv6 string) {
+	return detectIPs(ctx, &http.Client{Timeout: 5 * time.Second})
+}
+
+// DetectPublicIPs is DetectIPs using the client's transport (custom CAs,
+// proxy) and context.
+func (c *Client) DetectPublicIPs() (ipv4, ipv6 string) {
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 5 * time.Second
+	return detectIPs(c.context(), &httpClient)
+}
+
+func detectIPs(ctx context.Context, httpClient *http.Client) (ipv4, ipv6 string) {
 	var wg sync.WaitGroup
 	wg.Add(2)
 
-	httpClient := &http.Client{Timeout: 5 * time.Second}
-
 	go func() {
 		defer wg.Done()
-		resp, err := httpClient.Get("https://api4.ipify.org?format=json")
-		if err != nil {
-			return
-		}
-		defer resp.Body.Close()
-		var result struct {
-			IP string `json:"ip"`
-		}
-		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.IP != "" && !strings.Contains(result.IP, ":") {
-			ipv4 = result.IP
+		if ip := detectIP(ctx, httpClient, IPv4DetectURL); ip != "" && !strings.Contains(ip, ":") {
+			ipv4 = ip
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		resp, err := httpClient.Get("https://api6.ipify.org?format=json")
-		if err != nil {
-			return
-		}
-		defer resp.Body.Close()
-		var result struct {
-			IP string `json:"ip"`
-		}
-		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.IP != "" && strings.Contains(result.IP, ":") {
-			ipv6 = result.IP
+		if ip := detectIP(ctx, httpClient, IPv6DetectURL); ip != "" && strings.Contains(ip, ":") {
+			ipv6 = ip
 		}
 	}()
 
@@ -206,76 +147,75 @@ func DetectIPs() (ipv4, ipv6 string) {
 	return
 }
 
-func (c *Client) DetectIP() (*IpResponse, error) {
-	data, err := c.do("GET", "/detect-ip", nil)
+func detectIP(ctx context.Context, httpClient *http.Client, url string) string {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return ""
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	var result struct {
+		IP string `json:"ip"`
 	}
-	var ip IpResponse
-	if err := json.Unmarshal(data, &ip); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if json.NewDecoder(resp.Body).Decode(&result) != nil {
+		return ""
 	}
-	return &ip, nil
+	return result.IP
+}
+
+func (c *Client) DetectIP() (*IpResponse, error) {
+	return c.sdk().DetectIP(c.context())
 }
 
 func (c *Client) StartSession(req *StartSessionRequest) (*Session, error) {
-	data, err := c.do("POST", "/sessions", req)
-	if err != nil {
-		return nil, err
-	}
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return &session, nil
+	return c.sdk().StartSession(c.context(), req)
 }
 
 func (c *Client) StopSession(id string) (*Session, error) {
-	data, err := c.do("POST", fmt.Sprintf("/sessions/%s/stop", id), struct{}{})
-	if err != nil {
-		return nil, err
-	}
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return &session, nil
+	return c.sdk().StopSession(c.context(), id)
 }
 
 func (c *Client) ListSessions() ([]Session, error) {
-	data, err := c.do("GET", "/sessions", nil)
-	if err != nil {
-		return nil, err
-	}
-	var sessions []Session
-	if err := json.Unmarshal(data, &sessions); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return sessions, nil
+	return c.sdk().ListSessions(c.context())
+}
+
+// ListOrgSessions returns the current sessions of every user in the
+// organization, optionally narrowed to one user (email or ID). Requires org
+// admin rights.
+func (c *Client) ListOrgSessions(user string) ([]Session, error) {
+	return c.sdk().ListOrgSessions(c.context(), user)
+}
+
+// StopOrgSession stops any user's session in the organization. Requires org
+// admin rights.
+func (c *Client) StopOrgSession(id string) (*Session, error) {
+	return c.sdk().StopOrgSession(c.context(), id)
+}
+
+// ListSessionHistory returns one page of past and current sessions.
+func (c *Client) ListSessionHistory(q SessionHistoryQuery) (*SessionPage, error) {
+	return c.sdk().ListSessionHistory(c.context(), q)
+}
+
+// ListAllSessionHistory pages through the session history until the last
+// page, or until limit sessions have been collected when limit > 0.
+func (c *Client) ListAllSessionHistory(q SessionHistoryQuery, limit int) ([]Session, error) {
+	return c.sdk().ListAllSessionHistory(c.context(), q, limit)
+}
+
+// ListAllOrgSessionHistory pages through the session history of every user
+// in the organization. Requires org admin rights.
+func (c *Client) ListAllOrgSessionHistory(q SessionHistoryQuery, limit int) ([]Session, error) {
+	return c.sdk().ListAllOrgSessionHistory(c.context(), q, limit)
 }
 
 func (c *Client) GetSession(id string) (*Session, error) {
-	data, err := c.do("GET", fmt.Sprintf("/sessions/%s", id), nil)
-	if err != nil {
-		return nil, err
-	}
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return &session, nil
+	return c.sdk().GetSession(c.context(), id)
 }
 
-func (c *Client) ExtendSession(id string, hours int) (*Session, error) {
-	data, err := c.do("POST", fmt.Sprintf("/sessions/%s/extend", id), &ExtendSessionRequest{
-		AdditionalHours: hours,
-	})
-	if err != nil {
-		return nil, err
-	}
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-	return &session, nil
+func (c *Client) ExtendSession(id string, req *ExtendSessionRequest) (*Session, error) {
+	return c.sdk().ExtendSession(c.context(), id, req)
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticTokens string
+
+func (s staticTokens) AccessToken(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+func TestAuthHeaders(t *testing.T) {
+	var apiKey, authz string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey, authz = r.Header.Get("X-API-Key"), r.Header.Get("Authorization")
+		meHandler(w, r)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "key-1")
+	if _, err := client.GetMe(); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "key-1" || authz != "" {
+		t.Errorf("API key mode: X-API-Key = %q, Authorization = %q", apiKey, authz)
+	}
+
+	client.Tokens = staticTokens("tok-1")
+	if _, err := client.WithContext(context.Background()).GetMe(); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "" || authz != "Bearer tok-1" {
+		t.Errorf("bearer mode: X-API-Key = %q, Authorization = %q", apiKey, authz)
+	}
+}
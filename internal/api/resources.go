@@ -0,0 +1,32 @@
+package api
+
+import "github.com/entryguard-io/cli/pkg/entryguard"
+
+type (
+	Resource        = entryguard.Resource
+	ResourceRequest = entryguard.ResourceRequest
+)
+
+// ListResources returns the resources the current user can whitelist on.
+func (c *Client) ListResources() ([]Resource, error) {
+	return c.sdk().ListResources(c.context())
+}
+
+func (c *Client) GetResource(id string) (*Resource, error) {
+	return c.sdk().GetResource(c.context(), id)
+}
+
+// CreateResource creates a resource. Requires org admin rights.
+func (c *Client) CreateResource(req *ResourceRequest) (*Resource, error) {
+	return c.sdk().CreateResource(c.context(), req)
+}
+
+// UpdateResource replaces a resource's settings. Requires org admin rights.
+func (c *Client) UpdateResource(id string, req *ResourceRequest) (*Resource, error) {
+	return c.sdk().UpdateResource(c.context(), id, req)
+}
+
+// DeleteResource deletes a resource. Requires org admin rights.
+func (c *Client) DeleteResource(id string) error {
+	return c.sdk().DeleteResource(c.context(), id)
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	idPrefixRegex = regexp.MustCompile(`^[0-9a-fA-F-]+$`)
+)
+
+// IsUUID reports whether s is a well-formed session ID.
+func IsUUID(s string) bool {
+	return uuidRegex.MatchString(s)
+}
+
+// AmbiguousSessionError is returned by MatchSession when a prefix matches
+// more than one session and none of them can be preferred.
+type AmbiguousSessionError struct {
+	Input      string
+	Candidates []Session
+}
+
+func (e *AmbiguousSessionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ambiguous session ID '%s' — matches %d sessions:", e.Input, len(e.Candidates))
+	for _, s := range e.Candidates {
+		ip := s.Ipv4Address
+		if ip == "" {
+			ip = s.Ipv6Address
+		}
+		fmt.Fprintf(&b, "\n  %s  %-9s %s", s.ID, s.Status, ip)
+	}
+	b.WriteString("\nUse a longer prefix.")
+	return b.String()
+}
+
+// MatchSession resolves a user-supplied session reference against a list of
+// sessions. Accepted forms:
+//
+//   - a full session UUID
+//   - a unique ID prefix; if several sessions match but exactly one of them
+//     is active, the active one wins
+//   - "latest" or "@1" for the most recently started session, "@2" for the
+//     one before it, and so on
+func MatchSession(sessions []Session, input string) (*Session, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("empty session ID")
+	}
+
+	if input == "latest" || strings.HasPrefix(input, "@") {
+		n := 1
+		if input != "latest" {
+			var err error
+			n, err = strconv.Atoi(input[1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid session alias '%s' (use latest, @1, @2, ...)", input)
+			}
+		}
+		recent := sortedByStart(sessions)
+		if n > len(recent) {
+			return nil, fmt.Errorf("no session %s: only %d sessions found", input, len(recent))
+		}
+		return &recent[n-1], nil
+	}
+
+	if !idPrefixRegex.MatchString(input) || len(input) > 36 {
+		return nil, fmt.Errorf("invalid session ID '%s'", input)
+	}
+
+	lower := strings.ToLower(input)
+	var matches []Session
+	for _, s := range sessions {
+		if strings.HasPrefix(strings.ToLower(s.ID), lower) {
+			matches = append(matches, s)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no session found matching '%s'", input)
+	case 1:
+		return &matches[0], nil
+	}
+
+	var active []Session
+	for _, s := range matches {
+		if s.IsActive() {
+			active = append(active, s)
+		}
+	}
+	if len(active) == 1 {
+		return &active[0], nil
+	}
+	return nil, &AmbiguousSessionError{Input: input, Candidates: matches}
+}
+
+// sortedByStart returns a copy of sessions ordered newest first.
+func sortedByStart(sessions []Session) []Session {
+	sorted := make([]Session, len(sessions))
+	copy(sorted, sessions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return startTime(sorted[i]).After(startTime(sorted[j]))
+	})
+	return sorted
+}
+
+func startTime(s Session) time.Time {
+	for _, ts := range []string{s.StartedAt, s.CreatedAt} {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// SessionsForUser returns the sessions belonging to user, matched against
+// the user ID, email or name (case-insensitive).
+func SessionsForUser(sessions []Session, user string) []Session {
+	var out []Session
+	for _, s := range sessions {
+		if s.UserID == user || strings.EqualFold(s.UserEmail, user) || strings.EqualFold(s.UserName, user) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// IgnoredUserFilter reports whether sessions, listed by the server for
+// user, evidently include other users' sessions: they belong to more than
+// one user ID, or one of them has an ID and email that both differ from
+// user. Sessions that don't say enough to tell are assumed to match, since
+// the server may match user on fields it doesn't return.
+func IgnoredUserFilter(sessions []Session, user string) bool {
+	var id string
+	for _, s := range sessions {
+		if s.UserID != "" {
+			if id != "" && s.UserID != id {
+				return true
+			}
+			id = s.UserID
+		}
+		if s.UserID != "" && s.UserEmail != "" && s.UserID != user &&
+			!strings.EqualFold(s.UserEmail, user) && !strings.EqualFold(s.UserName, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoveringSession returns the first active session that whitelists ip, or nil.
+func CoveringSession(sessions []Session, ip string) *Session {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+	for i := range sessions {
+		if sessions[i].IsActive() && sessions[i].Covers(addr) {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestCovers(t *testing.T) {
+	s := Session{
+		Status:      "ACTIVE",
+		Ipv4Address: "203.0.113.10",
+		ResourceIps: []SessionResourceIp{
+			{IpAddress: "2001:db8:1::/64", Status: "APPLIED"},
+			{IpAddress: "198.51.100.0/24", Status: "REMOVED"},
+		},
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"203.0.113.10", true},
+		{"203.0.113.11", false},
+		{"2001:db8:1::42", true},
+		{"2001:db8:2::1", false},
+		{"198.51.100.7", false}, // rule was removed
+	}
+	for _, tt := range tests {
+		if got := s.Covers(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("Covers(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCoveringSession(t *testing.T) {
+	sessions := []Session{
+		{ID: "expired", Status: "EXPIRED", Ipv4Address: "203.0.113.10"},
+		{ID: "active", Status: "ACTIVE", Ipv4Address: "203.0.113.0/28"},
+	}
+
+	if s := CoveringSession(sessions, "203.0.113.10"); s == nil || s.ID != "active" {
+		t.Errorf("expected active session to cover IP, got %+v", s)
+	}
+	if s := CoveringSession(sessions, "203.0.113.99"); s != nil {
+		t.Errorf("expected no covering session, got %s", s.ID)
+	}
+	if s := CoveringSession(sessions, "not-an-ip"); s != nil {
+		t.Errorf("expected nil for invalid IP, got %s", s.ID)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s := Session{ExpiresAt: "2024-05-01T10:30:00Z"}
+
+	d, ok := s.Remaining(now)
+	if !ok || d != 30*time.Minute {
+		t.Errorf("Remaining = %s, %v; want 30m, true", d, ok)
+	}
+
+	if _, ok := (&Session{}).Remaining(now); ok {
+		t.Error("expected ok=false for missing expiry")
+	}
+}
+
+func TestMatchSession(t *testing.T) {
+	sessions := []Session{
+		{ID: "aaaa1111-0000-4000-8000-000000000001", Status: "EXPIRED", StartedAt: "2024-05-01T08:00:00Z"},
+		{ID: "aaaa2222-0000-4000-8000-000000000002", Status: "ACTIVE", StartedAt: "2024-05-03T08:00:00Z"},
+		{ID: "bbbb3333-0000-4000-8000-000000000003", Status: "EXPIRED", StartedAt: "2024-05-02T08:00:00Z"},
+		{ID: "bbbb4444-0000-4000-8000-000000000004", Status: "CANCELLED", StartedAt: "2024-04-30T08:00:00Z"},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"aaaa1", "aaaa1111-0000-4000-8000-000000000001"},
+		{"AAAA2", "aaaa2222-0000-4000-8000-000000000002"},
+		{"aaaa", "aaaa2222-0000-4000-8000-000000000002"}, // only active match wins
+		{"latest", "aaaa2222-0000-4000-8000-000000000002"},
+		{"@1", "aaaa2222-0000-4000-8000-000000000002"},
+		{"@2", "bbbb3333-0000-4000-8000-000000000003"},
+		{"bbbb4444-0000-4000-8000-000000000004", "bbbb4444-0000-4000-8000-000000000004"},
+	}
+	for _, tt := range tests {
+		s, err := MatchSession(sessions, tt.input)
+		if err != nil {
+			t.Errorf("MatchSession(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if s.ID != tt.want {
+			t.Errorf("MatchSession(%q) = %s, want %s", tt.input, s.ID, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "cccc", "@0", "@9", "@x", "not-an-id!", "xyz"} {
+		if _, err := MatchSession(sessions, input); err == nil {
+			t.Errorf("MatchSession(%q): expected error", input)
+		}
+	}
+
+	_, err := MatchSession(sessions, "bbbb")
+	amb, ok := err.(*AmbiguousSessionError)
+	if !ok {
+		t.Fatalf("expected AmbiguousSessionError, got %v", err)
+	}
+	if len(amb.Candidates) != 2 {
+		t.Errorf("expected 2 candidates, got %d", len(amb.Candidates))
+	}
+}
+
+func TestIsUUID(t *testing.T) {
+	if !IsUUID("aaaa1111-0000-4000-8000-000000000001") {
+		t.Error("expected valid UUID")
+	}
+	for _, s := range []string{"", "aaaa1111", "aaaa1111-0000-4000-8000-00000000000z", "aaaa1111-0000-4000-8000-0000000000011"} {
+		if IsUUID(s) {
+			t.Errorf("IsUUID(%q) = true, want false", s)
+		}
+	}
+}
+
+func TestSessionsForUser(t *testing.T) {
+	sessions := []Session{
+		{ID: "1", UserID: "u1", UserEmail: "alice@example.com", UserName: "Alice"},
+		{ID: "2", UserID: "u2", UserEmail: "bob@example.com", UserName: "Bob"},
+		{ID: "3", UserID: "u1", UserEmail: "alice@example.com", UserName: "Alice"},
+	}
+
+	for _, user := range []string{"u1", "Alice@Example.com", "alice"} {
+		got := SessionsForUser(sessions, user)
+		if len(got) != 2 || got[0].ID != "1" || got[1].ID != "3" {
+			t.Errorf("SessionsForUser(%q) = %v", user, got)
+		}
+	}
+	if got := SessionsForUser(sessions, "carol"); len(got) != 0 {
+		t.Errorf("SessionsForUser(carol) = %v, want none", got)
+	}
+}
+
+func TestIgnoredUserFilter(t *testing.T) {
+	// Filtered by email on the server, but only IDs are returned.
+	idOnly := []Session{{ID: "1", UserID: "u1"}, {ID: "2", UserID: "u1"}}
+	if IgnoredUserFilter(idOnly, "alice@example.com") {
+		t.Error("sessions of a single user ID were taken as unfiltered")
+	}
+	if IgnoredUserFilter(nil, "alice@example.com") {
+		t.Error("an empty listing was taken as unfiltered")
+	}
+
+	// Several users, or a fully known different user.
+	if !IgnoredUserFilter([]Session{{UserID: "u1"}, {UserID: "u2"}}, "alice@example.com") {
+		t.Error("sessions of two users were taken as filtered")
+	}
+	bob := []Session{{UserID: "u2", UserEmail: "bob@example.com"}}
+	if !IgnoredUserFilter(bob, "alice@example.com") {
+		t.Error("bob's session was taken as alice's")
+	}
+	if IgnoredUserFilter(bob, "Bob@Example.com") || IgnoredUserFilter(bob, "u2") {
+		t.Error("bob's session was taken as someone else's")
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportConfig customises how the client connects: extra trusted CAs,
+// a client certificate for mTLS, and an explicit proxy. The zero value uses
+// the system trust store and the proxy from the environment.
+type TransportConfig struct {
+	CAFile             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	// ProxyURL overrides HTTPS_PROXY/HTTP_PROXY. "direct" disables proxying.
+	ProxyURL string
+}
+
+// TLSConfig builds the TLS configuration. CAFile is added to the system
+// roots rather than replacing them, so public endpoints such as IP detection
+// keep working.
+func (t TransportConfig) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file %s contains no PEM certificates", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		if t.ClientCert == "" || t.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Transport builds an http.Transport from the configuration.
+func (t TransportConfig) Transport() (*http.Transport, error) {
+	tlsCfg, err := t.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsCfg
+
+	switch t.ProxyURL {
+	case "":
+	case "direct":
+		tr.Proxy = nil
+	default:
+		u, err := url.Parse(t.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q", t.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https or socks5", t.ProxyURL)
+		}
+		tr.Proxy = http.ProxyURL(u)
+	}
+	return tr, nil
+}
+
+// NewClientWithTransport is NewClient with a customised transport.
+func NewClientWithTransport(baseURL, apiKey string, t TransportConfig) (*Client, error) {
+	tr, err := t.Transport()
+	if err != nil {
+		return nil, err
+	}
+	c := NewClient(baseURL, apiKey)
+	c.HTTPClient.Transport = tr
+	return c, nil
+}
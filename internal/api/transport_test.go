@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func meHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"email":"a@example.com"}`))
+}
+
+func TestTransportCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(meHandler))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "k").GetMe(); err == nil {
+		t.Fatal("expected an untrusted certificate to fail without ca_file")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	client, err := NewClientWithTransport(srv.URL, "k", TransportConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMe(); err != nil {
+		t.Fatalf("GetMe with ca_file: %v", err)
+	}
+
+	client, err = NewClientWithTransport(srv.URL, "k", TransportConfig{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMe(); err != nil {
+		t.Fatalf("GetMe with insecure_skip_verify: %v", err)
+	}
+}
+
+func TestTransportClientCert(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "eg-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "eg-test-client" {
+			http.Error(w, `{"message":"no client certificate"}`, http.StatusUnauthorized)
+			return
+		}
+		meHandler(w, r)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, err := NewClientWithTransport(srv.URL, "k", TransportConfig{
+		InsecureSkipVerify: true,
+		ClientCert:         certFile,
+		ClientKey:          keyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMe(); err != nil {
+		t.Fatalf("GetMe with client certificate: %v", err)
+	}
+}
+
+func TestTransportConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  TransportConfig
+	}{
+		{"missing ca_file", TransportConfig{CAFile: filepath.Join(dir, "missing.pem")}},
+		{"ca_file without certificates", TransportConfig{CAFile: notPEM}},
+		{"cert without key", TransportConfig{ClientCert: notPEM}},
+		{"proxy without host", TransportConfig{ProxyURL: "proxy.example.com:3128"}},
+		{"proxy with bad scheme", TransportConfig{ProxyURL: "ftp://proxy.example.com"}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.cfg.Transport(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	for _, proxy := range []string{"", "direct", "http://proxy.example.com:3128", "socks5://127.0.0.1:1080"} {
+		if _, err := (TransportConfig{ProxyURL: proxy}).Transport(); err != nil {
+			t.Errorf("proxy %q: %v", proxy, err)
+		}
+	}
+}
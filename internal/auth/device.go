@@ -0,0 +1,219 @@
+// Package auth implements the OAuth 2.0 device authorization grant
+// (RFC 8628) used by `eg login`, and refreshing the resulting tokens.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientID identifies the CLI to the authorization server.
+const ClientID = "eg-cli"
+
+// Endpoints are relative to the API base URL.
+const (
+	deviceCodePath = "/oauth/device/code"
+	tokenPath      = "/oauth/token"
+
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+var (
+	ErrAccessDenied = errors.New("login was denied in the browser")
+	ErrExpired      = errors.New("login code expired before it was approved")
+)
+
+// Config describes the authorization server.
+type Config struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Scope      string
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// DeviceCode is the response to a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// Token is an access token with its refresh token.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// Valid reports whether the access token can be used at now, leaving a
+// margin for clock skew and request latency.
+func (t *Token) Valid(now time.Time) bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || now.Add(time.Minute).Before(t.Expiry))
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// oauthError is the error body defined by RFC 6749 section 5.2.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// RequestDeviceCode starts a device authorization.
+func RequestDeviceCode(ctx context.Context, cfg *Config) (*DeviceCode, error) {
+	form := url.Values{"client_id": {ClientID}}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	var dc DeviceCode
+	if err := postForm(ctx, cfg, deviceCodePath, form, &dc); err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	if dc.DeviceCode == "" || dc.UserCode == "" || dc.VerificationURI == "" {
+		return nil, fmt.Errorf("device authorization failed: incomplete response from server")
+	}
+	return &dc, nil
+}
+
+// after waits between polls; tests replace it to poll without delay.
+var after = time.After
+
+// PollToken polls the token endpoint until the user approves or denies the
+// request, the code expires, or ctx is done.
+func PollToken(ctx context.Context, cfg *Config, dc *DeviceCode) (*Token, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	var deadline time.Time
+	if dc.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	}
+
+	form := url.Values{
+		"grant_type":  {deviceGrantType},
+		"device_code": {dc.DeviceCode},
+		"client_id":   {ClientID},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-after(interval):
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, ErrExpired
+		}
+
+		token, err := requestToken(ctx, cfg, form)
+		var oe *oauthError
+		if errors.As(err, &oe) {
+			switch oe.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "access_denied":
+				return nil, ErrAccessDenied
+			case "expired_token":
+				return nil, ErrExpired
+			}
+		}
+		return token, err
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token. Servers that do
+// not rotate refresh tokens omit it; the old one is kept in that case.
+func Refresh(ctx context.Context, cfg *Config, refreshToken string) (*Token, error) {
+	token, err := requestToken(ctx, cfg, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {ClientID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token refresh failed: %w", err)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+func requestToken(ctx context.Context, cfg *Config, form url.Values) (*Token, error) {
+	var resp tokenResponse
+	if err := postForm(ctx, cfg, tokenPath, form, &resp); err != nil {
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+	if resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", resp.TokenType)
+	}
+	token := &Token{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func postForm(ctx context.Context, cfg *Config, path string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(cfg.BaseURL, "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var oe oauthError
+		if json.Unmarshal(body, &oe) == nil && oe.Code != "" {
+			return &oe
+		}
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
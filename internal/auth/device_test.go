@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a minimal device-flow authorization server. The user
+// "approves" after pending polls; slowDown polls answer slow_down first.
+type fakeServer struct {
+	mu       sync.Mutex
+	pending  int
+	slowDown int
+	deny     bool
+	polls    int
+	refresh  int
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil || r.Form.Get("client_id") != ClientID {
+		writeJSON(w, 400, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v1/oauth/device/code":
+		writeJSON(w, 200, map[string]any{
+			"device_code":      "dev-123",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": "https://eg.example.com/device",
+			"expires_in":       600,
+			"interval":         1,
+		})
+	case "/api/v1/oauth/token":
+		switch r.Form.Get("grant_type") {
+		case deviceGrantType:
+			f.polls++
+			switch {
+			case r.Form.Get("device_code") != "dev-123":
+				writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+			case f.slowDown > 0:
+				f.slowDown--
+				writeJSON(w, 400, map[string]string{"error": "slow_down"})
+			case f.pending > 0:
+				f.pending--
+				writeJSON(w, 400, map[string]string{"error": "authorization_pending"})
+			case f.deny:
+				writeJSON(w, 400, map[string]string{"error": "access_denied"})
+			default:
+				writeJSON(w, 200, map[string]any{"access_token": "at-1", "refresh_token": "rt-1", "token_type": "Bearer", "expires_in": 3600})
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "rt-1" {
+				writeJSON(w, 400, map[string]string{"error": "invalid_grant", "error_description": "refresh token revoked"})
+				return
+			}
+			f.refresh++
+			writeJSON(w, 200, map[string]any{"access_token": "at-2", "token_type": "bearer", "expires_in": 3600})
+		default:
+			writeJSON(w, 400, map[string]string{"error": "unsupported_grant_type"})
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// noWait makes PollToken poll without sleeping and records the intervals it
+// asked for.
+func noWait(t *testing.T) *[]time.Duration {
+	var waits []time.Duration
+	orig := after
+	after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+	t.Cleanup(func() { after = orig })
+	return &waits
+}
+
+func TestDeviceFlow(t *testing.T) {
+	waits := noWait(t)
+	fake := &fakeServer{pending: 2, slowDown: 1}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	cfg := &Config{BaseURL: srv.URL + "/api/v1", HTTPClient: srv.Client()}
+
+	dc, err := RequestDeviceCode(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.UserCode != "ABCD-EFGH" {
+		t.Errorf("UserCode = %q", dc.UserCode)
+	}
+
+	token, err := PollToken(context.Background(), cfg, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "at-1" || token.RefreshToken != "rt-1" {
+		t.Errorf("token = %+v", token)
+	}
+	if !token.Valid(time.Now()) || token.Valid(time.Now().Add(2*time.Hour)) {
+		t.Errorf("unexpected validity for expiry %v", token.Expiry)
+	}
+	if fake.polls != 4 {
+		t.Errorf("polls = %d, want 4", fake.polls)
+	}
+	// slow_down on the first poll adds 5s to every later wait.
+	want := []time.Duration{time.Second, 6 * time.Second, 6 * time.Second, 6 * time.Second}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", *waits, want)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Errorf("waits = %v, want %v", *waits, want)
+			break
+		}
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	noWait(t)
+	srv := httptest.NewServer(&fakeServer{pending: 1, deny: true})
+	defer srv.Close()
+	cfg := &Config{BaseURL: srv.URL + "/api/v1"}
+
+	dc, err := RequestDeviceCode(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PollToken(context.Background(), cfg, dc); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("err = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestSourceRefresh(t *testing.T) {
+	fake := &fakeServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	cfg := &Config{BaseURL: srv.URL + "/api/v1"}
+
+	var saved *Token
+	src := NewSource(cfg, Token{AccessToken: "at-1", RefreshToken: "rt-1", Expiry: time.Now().Add(time.Hour)})
+	src.OnRefresh = func(tok *Token) { saved = tok }
+
+	if tok, err := src.AccessToken(context.Background()); err != nil || tok != "at-1" {
+		t.Fatalf("valid token: got %q, %v", tok, err)
+	}
+	if fake.refresh != 0 {
+		t.Fatal("refreshed a valid token")
+	}
+
+	src = NewSource(cfg, Token{AccessToken: "at-1", RefreshToken: "rt-1", Expiry: time.Now().Add(10 * time.Second)})
+	src.OnRefresh = func(tok *Token) { saved = tok }
+	tok, err := src.AccessToken(context.Background())
+	if err != nil || tok != "at-2" {
+		t.Fatalf("expiring token: got %q, %v", tok, err)
+	}
+	// The server did not rotate the refresh token, so the old one is kept.
+	if saved == nil || saved.AccessToken != "at-2" || saved.RefreshToken != "rt-1" {
+		t.Errorf("OnRefresh got %+v", saved)
+	}
+
+	src = NewSource(cfg, Token{AccessToken: "at-0", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)})
+	if _, err := src.AccessToken(context.Background()); err == nil {
+		t.Error("expected refresh with a revoked token to fail")
+	}
+}
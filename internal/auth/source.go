@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Source hands out access tokens for API requests, refreshing the token
+// when it is about to expire. It satisfies api.TokenSource.
+type Source struct {
+	cfg *Config
+
+	// OnRefresh is called with each newly refreshed token so it can be
+	// persisted. Errors are the caller's to report.
+	OnRefresh func(*Token)
+
+	mu    sync.Mutex
+	token Token
+}
+
+// NewSource returns a Source starting from token.
+func NewSource(cfg *Config, token Token) *Source {
+	return &Source{cfg: cfg, token: token}
+}
+
+// AccessToken returns a valid access token, refreshing it first if needed.
+func (s *Source) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid(time.Now()) {
+		return s.token.AccessToken, nil
+	}
+	if s.token.RefreshToken == "" {
+		return "", fmt.Errorf("login expired. Run: eg login")
+	}
+
+	token, err := Refresh(ctx, s.cfg, s.token.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("%w. Run: eg login", err)
+	}
+	s.token = *token
+	if s.OnRefresh != nil {
+		s.OnRefresh(token)
+	}
+	return token.AccessToken, nil
+}
+
+// Token returns the current token.
+func (s *Source) Token() Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
@@ -0,0 +1,258 @@
+// Package cache keeps small local snapshots of API state so that latency-
+// sensitive callers (shell prompts, completion) don't have to hit the API.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+)
+
+// SessionState is the cached view of a profile's active sessions.
+type SessionState struct {
+	Profile   string        `json:"profile"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	Sessions  []api.Session `json:"sessions"`
+}
+
+// ResourceState is the cached list of resources a profile can whitelist on.
+type ResourceState struct {
+	Profile   string         `json:"profile"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	Resources []api.Resource `json:"resources"`
+}
+
+// Age returns how long ago the state was written.
+func (s *SessionState) Age(now time.Time) time.Duration {
+	return now.Sub(s.UpdatedAt)
+}
+
+// Age returns how long ago the resource list was written.
+func (s *ResourceState) Age(now time.Time) time.Duration {
+	return now.Sub(s.UpdatedAt)
+}
+
+// Active returns the cached sessions that are active and not yet expired.
+func (s *SessionState) Active(now time.Time) []api.Session {
+	var active []api.Session
+	for _, sess := range s.Sessions {
+		if !sess.IsActive() {
+			continue
+		}
+		if d, ok := sess.Remaining(now); ok && d <= 0 {
+			continue
+		}
+		active = append(active, sess)
+	}
+	return active
+}
+
+// Dir returns the cache directory, ~/.entryguard/cache.
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+func path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// profileFile names the cache file of one kind for a profile. The profile
+// name is escaped so names with path separators stay inside the cache
+// directory.
+func profileFile(kind, profile string) string {
+	return kind + "-" + url.PathEscape(profile) + ".json"
+}
+
+func sessionsFile(profile string) string {
+	return profileFile("sessions", profile)
+}
+
+// ReadSessions loads the cached session state for a profile.
+func ReadSessions(profile string) (*SessionState, error) {
+	var state SessionState
+	if err := readJSON(sessionsFile(profile), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// WriteSessions replaces the cached session state for a profile. Only active
+// sessions are kept; history is not useful to cache readers.
+func WriteSessions(profile string, sessions []api.Session) error {
+	state := SessionState{Profile: profile, UpdatedAt: time.Now()}
+	for _, s := range sessions {
+		if s.IsActive() || s.Status == "PENDING" {
+			state.Sessions = append(state.Sessions, s)
+		}
+	}
+	return writeJSON(sessionsFile(profile), &state)
+}
+
+// UpsertSession updates a single session in the cache after a start, stop or
+// extend, without refetching the whole list. The cache age is not changed.
+func UpsertSession(profile string, session *api.Session) error {
+	state, err := ReadSessions(profile)
+	if err != nil {
+		state = &SessionState{Profile: profile, UpdatedAt: time.Now()}
+	}
+
+	sessions := make([]api.Session, 0, len(state.Sessions)+1)
+	for _, s := range state.Sessions {
+		if s.ID != session.ID {
+			sessions = append(sessions, s)
+		}
+	}
+	if session.IsActive() || session.Status == "PENDING" {
+		sessions = append(sessions, *session)
+	}
+	state.Sessions = sessions
+	return writeJSON(sessionsFile(profile), state)
+}
+
+func resourcesFile(profile string) string {
+	return profileFile("resources", profile)
+}
+
+// ReadResources loads the cached resource list for a profile.
+func ReadResources(profile string) (*ResourceState, error) {
+	var state ResourceState
+	if err := readJSON(resourcesFile(profile), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// WriteResources replaces the cached resource list for a profile.
+func WriteResources(profile string, resources []api.Resource) error {
+	return writeJSON(resourcesFile(profile), &ResourceState{
+		Profile:   profile,
+		UpdatedAt: time.Now(),
+		Resources: resources,
+	})
+}
+
+// ClearResources drops the cached resource list, e.g. after an admin change.
+func ClearResources(profile string) error {
+	p, err := path(resourcesFile(profile))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// HookState records the session events whose hooks have run for a profile,
+// so eg session watch doesn't repeat hooks that session start, stop or
+// extend already ran, and vice versa.
+type HookState struct {
+	Fired map[string]time.Time `json:"fired"`
+}
+
+// hookMemory is how long fired events are remembered.
+const hookMemory = 7 * 24 * time.Hour
+
+func hooksFile(profile string) string {
+	return profileFile("hooks", profile)
+}
+
+// MarkHookFired records that the hooks for event have run for a session and
+// reports whether they already had. expiresAt tells apart repeated events
+// of one session, such as successive extensions; pass "" for events that
+// happen once.
+func MarkHookFired(profile, sessionID, event, expiresAt string) (fired bool, err error) {
+	var state HookState
+	readJSON(hooksFile(profile), &state)
+	if state.Fired == nil {
+		state.Fired = make(map[string]time.Time)
+	}
+
+	key := sessionID + " " + event + " " + expiresAt
+	if _, ok := state.Fired[key]; ok {
+		return true, nil
+	}
+	now := time.Now()
+	for k, t := range state.Fired {
+		if now.Sub(t) > hookMemory {
+			delete(state.Fired, k)
+		}
+	}
+	state.Fired[key] = now
+	return false, writeJSON(hooksFile(profile), &state)
+}
+
+// Touch records that a refresh for key was started and reports whether the
+// previous one began less than within ago, letting callers avoid piling up
+// background refreshes.
+func Touch(key string, within time.Duration) (recent bool, err error) {
+	p, err := path(key + ".lock")
+	if err != nil {
+		return false, err
+	}
+	if fi, err := os.Stat(p); err == nil && time.Since(fi.ModTime()) < within {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return false, err
+	}
+	return false, os.WriteFile(p, nil, 0600)
+}
+
+func readJSON(name string, v any) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("corrupt cache file %s: %w", p, err)
+	}
+	return nil
+}
+
+// writeJSON writes atomically so concurrent readers never see a partial file.
+func writeJSON(name string, v any) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), name+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+)
+
+func TestSessionsRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	err := WriteSessions("prod", []api.Session{
+		{ID: "a", Status: "ACTIVE", ExpiresAt: future},
+		{ID: "b", Status: "EXPIRED"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := ReadSessions("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Sessions) != 1 || state.Sessions[0].ID != "a" {
+		t.Fatalf("expected only the active session to be cached, got %+v", state.Sessions)
+	}
+
+	// Stopping a session removes it from the cache
+	if err := UpsertSession("prod", &api.Session{ID: "a", Status: "CANCELLED"}); err != nil {
+		t.Fatal(err)
+	}
+	state, _ = ReadSessions("prod")
+	if len(state.Sessions) != 0 {
+		t.Fatalf("expected empty cache after stop, got %+v", state.Sessions)
+	}
+}
+
+func TestActive_dropsExpired(t *testing.T) {
+	now := time.Now()
+	state := SessionState{Sessions: []api.Session{
+		{ID: "live", Status: "ACTIVE", ExpiresAt: now.Add(time.Minute).Format(time.RFC3339)},
+		{ID: "stale", Status: "ACTIVE", ExpiresAt: now.Add(-time.Minute).Format(time.RFC3339)},
+	}}
+	active := state.Active(now)
+	if len(active) != 1 || active[0].ID != "live" {
+		t.Fatalf("expected only the live session, got %+v", active)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	recent, err := Touch("refresh-prod", time.Minute)
+	if err != nil || recent {
+		t.Fatalf("first Touch = %v, %v; want false, nil", recent, err)
+	}
+	recent, err = Touch("refresh-prod", time.Minute)
+	if err != nil || !recent {
+		t.Fatalf("second Touch = %v, %v; want true, nil", recent, err)
+	}
+}
+
+func TestMarkHookFired(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, tc := range []struct {
+		id, event, expires string
+		want               bool
+	}{
+		{"a", "session.started", "", false},
+		{"a", "session.started", "", true},
+		{"a", "session.extended", "2024-05-01T10:00:00Z", false},
+		{"a", "session.extended", "2024-05-01T11:00:00Z", false},
+		{"a", "session.extended", "2024-05-01T11:00:00Z", true},
+		{"b", "session.started", "", false},
+	} {
+		fired, err := MarkHookFired("prod", tc.id, tc.event, tc.expires)
+		if err != nil || fired != tc.want {
+			t.Errorf("MarkHookFired(%s, %s, %s) = %v, %v; want %v", tc.id, tc.event, tc.expires, fired, err, tc.want)
+		}
+	}
+	if fired, _ := MarkHookFired("dev", "a", "session.started", ""); fired {
+		t.Error("events are shared between profiles")
+	}
+}
+
+func TestProfileNamesStayInCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, profile := range []string{"../../escape", "a/b", `a\b`, ".."} {
+		if err := WriteSessions(profile, nil); err != nil {
+			t.Fatalf("%q: %v", profile, err)
+		}
+		if _, err := ReadSessions(profile); err != nil {
+			t.Errorf("%q: read back: %v", profile, err)
+		}
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("expected 4 cache files in %s, got %d", dir, len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(home, "escape")); err == nil {
+		t.Error("cache file written outside the cache directory")
+	}
+}
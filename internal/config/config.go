@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sync"
 
 	toml "github.com/pelletier/go-toml/v2"
 )
@@ -11,11 +13,67 @@ import (
 type Profile struct {
 	APIKey string `toml:"api_key"`
 	APIURL string `toml:"api_url"`
+
+	// Session policy enforced client-side before a session is started.
+	// Setting ReasonPattern implies RequireReason.
+	RequireReason bool   `toml:"require_reason,omitempty"`
+	ReasonPattern string `toml:"reason_pattern,omitempty"`
+
+	// Connection settings for self-hosted servers. CAFile is trusted in
+	// addition to the system roots; ProxyURL overrides the proxy environment
+	// variables ("direct" disables proxying).
+	CAFile             string `toml:"ca_file,omitempty"`
+	ClientCert         string `toml:"client_cert,omitempty"`
+	ClientKey          string `toml:"client_key,omitempty"`
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify,omitempty"`
+	ProxyURL           string `toml:"proxy_url,omitempty"`
+
+	// TunnelHost is the edge host for tunnel resources, overriding the one
+	// the API reports (e.g. a regional or private edge).
+	TunnelHost string `toml:"tunnel_host,omitempty"`
+
+	// OAuth tokens stored by `eg login`; used instead of APIKey when set.
+	// TokenExpiry is RFC 3339.
+	AccessToken  string `toml:"access_token,omitempty"`
+	RefreshToken string `toml:"refresh_token,omitempty"`
+	TokenExpiry  string `toml:"token_expiry,omitempty"`
+
+	Hooks Hooks `toml:"hooks,omitempty"`
+}
+
+// UsesLogin reports whether the profile authenticates with tokens from
+// `eg login` rather than an API key.
+func (p *Profile) UsesLogin() bool {
+	return p.AccessToken != "" || p.RefreshToken != ""
+}
+
+// CheckReason validates a session reason against the profile's policy.
+func (p *Profile) CheckReason(reason string) error {
+	if (p.RequireReason || p.ReasonPattern != "") && reason == "" {
+		return fmt.Errorf("this profile requires a reason for every session. Use: --reason \"...\"")
+	}
+	if p.ReasonPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.ReasonPattern)
+	if err != nil {
+		return fmt.Errorf("invalid reason_pattern %q in profile: %w", p.ReasonPattern, err)
+	}
+	if !re.MatchString(reason) {
+		return fmt.Errorf("reason %q does not match the profile's required pattern %s", reason, p.ReasonPattern)
+	}
+	return nil
 }
 
 type Config struct {
 	DefaultProfile string             `toml:"default_profile"`
 	Profiles       map[string]Profile `toml:"profiles"`
+	Presets        map[string]Preset  `toml:"presets,omitempty"`
+}
+
+// Dir returns the directory holding the config file and local caches.
+func Dir() (string, error) {
+	return configDir()
 }
 
 func configDir() (string, error) {
@@ -26,6 +84,11 @@ func configDir() (string, error) {
 	return filepath.Join(home, ".entryguard"), nil
 }
 
+// Path returns the location of the config file.
+func Path() (string, error) {
+	return configPath()
+}
+
 func configPath() (string, error) {
 	dir, err := configDir()
 	if err != nil {
@@ -43,7 +106,7 @@ func Load() (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &Config{Profiles: make(map[string]Profile)}, nil
+			return &Config{Profiles: make(map[string]Profile), Presets: make(map[string]Preset)}, nil
 		}
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
@@ -55,10 +118,15 @@ func Load() (*Config, error) {
 	if cfg.Profiles == nil {
 		cfg.Profiles = make(map[string]Profile)
 	}
+	if cfg.Presets == nil {
+		cfg.Presets = make(map[string]Preset)
+	}
 	return &cfg, nil
 }
 
-func Save(cfg *Config) error {
+// save writes cfg to the config file. It takes no lock; callers go through
+// Update.
+func save(cfg *Config) error {
 	dir, err := configDir()
 	if err != nil {
 		return err
@@ -72,8 +140,64 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	path := filepath.Join(dir, "config.toml")
-	return os.WriteFile(path, data, 0600)
+	// Write a temporary file and rename it into place, so readers that take
+	// no lock never see a half-written config.
+	tmp, err := os.CreateTemp(dir, "config.toml.*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, "config.toml"))
+}
+
+// updateMu serializes Update calls within this process; the file lock taken
+// by Update only excludes other processes.
+var updateMu sync.Mutex
+
+// Update re-reads the config, applies fn and saves the result while holding
+// a lock on the config file, so concurrent updates from this and other eg
+// processes are applied one after another instead of overwriting each other.
+// Nothing is saved if fn returns an error.
+func Update(fn func(cfg *Config) error) error {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "config.toml.lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open config lock: %w", err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock config: %w", err)
+	}
+	defer unlockFile(f)
+
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(cfg); err != nil {
+		return err
+	}
+	if err := save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
 }
 
 func GetProfile(cfg *Config, name string) (*Profile, error) {
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheckReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		reason  string
+		wantErr string // substring of the error; "" for none
+	}{
+		{"no policy, empty reason", Profile{}, "", ""},
+		{"no policy, any reason", Profile{}, "deploy", ""},
+		{"required, empty reason", Profile{RequireReason: true}, "", "requires a reason"},
+		{"required, given", Profile{RequireReason: true}, "deploy", ""},
+		{"pattern implies required", Profile{ReasonPattern: `^[A-Z]+-\d+`}, "", "requires a reason"},
+		{"pattern matches", Profile{ReasonPattern: `^[A-Z]+-\d+`}, "OPS-123 rotate certs", ""},
+		{"pattern does not match", Profile{ReasonPattern: `^[A-Z]+-\d+`}, "rotate certs", "does not match"},
+		{"invalid pattern", Profile{ReasonPattern: `([`}, "OPS-123", "invalid reason_pattern"},
+	}
+	for _, tt := range tests {
+		err := tt.profile.CheckReason(tt.reason)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUpdateReplacesConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	for _, name := range []string{"a", "b"} {
+		err := Update(func(cfg *Config) error {
+			AddProfile(cfg, name, Profile{APIKey: "key-" + name})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != 2 || cfg.DefaultProfile != "a" || cfg.Profiles["b"].APIKey != "key-b" {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	dir, _ := Dir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "config.toml" && e.Name() != "config.toml.lock" {
+			t.Errorf("leftover file %s", e.Name())
+		}
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "config.toml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("config mode = %04o, want 0600", perm)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/duration"
+)
+
+// DefaultExpiringBefore is how long before expiry on_session_expiring hooks
+// run when the profile does not say.
+const DefaultExpiringBefore = 10 * time.Minute
+
+// Hooks are local actions run on session lifecycle events. Each entry is
+// either an http(s) URL, which receives the event as a JSON POST, or a
+// shell command, which receives it on stdin.
+type Hooks struct {
+	OnSessionStart    []string `toml:"on_session_start,omitempty"`
+	OnSessionExpiring []string `toml:"on_session_expiring,omitempty"`
+	OnSessionEnd      []string `toml:"on_session_end,omitempty"`
+
+	// ExpiringBefore is a duration such as "15m".
+	ExpiringBefore string `toml:"expiring_before,omitempty"`
+}
+
+// Expiring returns the lead time for on_session_expiring hooks.
+func (h *Hooks) Expiring() (time.Duration, error) {
+	if h.ExpiringBefore == "" {
+		return DefaultExpiringBefore, nil
+	}
+	d, err := duration.Parse(h.ExpiringBefore)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hooks.expiring_before %q: %w", h.ExpiringBefore, err)
+	}
+	return d, nil
+}
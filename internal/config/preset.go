@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// ProjectFile is the name of the per-project config file, looked up from the
+// working directory upwards. It only holds presets, so it is safe to commit.
+const ProjectFile = ".entryguard.toml"
+
+// Preset is a named set of `eg session start` options.
+type Preset struct {
+	Duration      string   `toml:"duration,omitempty"`
+	Resources     []string `toml:"resources,omitempty"`
+	Exclude       []string `toml:"exclude,omitempty"`
+	Ipv4Prefix    int      `toml:"ipv4_prefix,omitempty"`
+	Ipv6Prefix    int      `toml:"ipv6_prefix,omitempty"`
+	Reason        string   `toml:"reason,omitempty"`
+	RequireReason bool     `toml:"require_reason,omitempty"`
+}
+
+// ProjectConfig is the content of a project's .entryguard.toml.
+type ProjectConfig struct {
+	Presets map[string]Preset `toml:"presets"`
+}
+
+// ReasonData is the data available to a preset's reason template.
+type ReasonData struct {
+	Reason string // value of --reason
+	Ticket string // value of --ticket
+	Preset string
+	User   string // local OS user
+	Date   string // today, YYYY-MM-DD
+}
+
+// RenderReason builds the session reason. Without a template the --reason
+// value is used as-is; otherwise the template is rendered, e.g.
+// "db maintenance: {{.Reason}}".
+func (p *Preset) RenderReason(data ReasonData) (string, error) {
+	if p.RequireReason && strings.TrimSpace(data.Reason) == "" {
+		return "", fmt.Errorf("preset %q requires a reason. Use: --reason \"...\"", data.Preset)
+	}
+	if p.Reason == "" {
+		return data.Reason, nil
+	}
+
+	if data.User == "" {
+		if u, err := user.Current(); err == nil {
+			data.User = u.Username
+		}
+	}
+	if data.Date == "" {
+		data.Date = time.Now().Format("2006-01-02")
+	}
+
+	tmpl, err := template.New("reason").Option("missingkey=error").Parse(p.Reason)
+	if err != nil {
+		return "", fmt.Errorf("invalid reason template in preset %q: %w", data.Preset, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid reason template in preset %q: %w", data.Preset, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// FindProjectFile walks up from dir looking for .entryguard.toml. It returns
+// "" if there is none.
+func FindProjectFile(dir string) string {
+	for {
+		p := filepath.Join(dir, ProjectFile)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the project config at path.
+func LoadProject(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+	var pc ProjectConfig
+	if err := toml.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if pc.Presets == nil {
+		pc.Presets = make(map[string]Preset)
+	}
+	return &pc, nil
+}
+
+// SaveProject writes the project config. The file is world-readable since it
+// is meant to be shared; it must not contain secrets.
+func SaveProject(path string, pc *ProjectConfig) error {
+	data, err := toml.Marshal(pc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project config: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// PresetEntry is a preset together with where it was defined.
+type PresetEntry struct {
+	Name   string
+	Source string // "user" or the project file path
+	Preset Preset
+	// Shadows is the project file whose preset of the same name this user
+	// preset hides, if any.
+	Shadows string
+}
+
+// Presets merges user presets with those of the nearest project config.
+// User presets take precedence over project presets of the same name, so a
+// cloned repository can't change what an existing preset does.
+func Presets(cfg *Config, cwd string) ([]PresetEntry, error) {
+	merged := make(map[string]PresetEntry)
+	if path := FindProjectFile(cwd); path != "" {
+		pc, err := LoadProject(path)
+		if err != nil {
+			return nil, err
+		}
+		for name, p := range pc.Presets {
+			merged[name] = PresetEntry{Name: name, Source: path, Preset: p}
+		}
+	}
+
+	for name, p := range cfg.Presets {
+		e := PresetEntry{Name: name, Source: "user", Preset: p}
+		if project, ok := merged[name]; ok {
+			e.Shadows = project.Source
+		}
+		merged[name] = e
+	}
+
+	entries := make([]PresetEntry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// GetPreset looks a preset up by name across user and project config.
+func GetPreset(cfg *Config, cwd, name string) (*PresetEntry, error) {
+	entries, err := Presets(cfg, cwd)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Name == name {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("preset %q not found. Run: eg preset list", name)
+}
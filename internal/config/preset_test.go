@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPresets_userOverridesProject(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "svc", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	project := "[presets.db]\nduration = \"2h\"\nresources = [\"db-*\"]\nrequire_reason = true\n" +
+		"[presets.ops]\nduration = \"4h\"\n"
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Presets: map[string]Preset{
+		"db":  {Duration: "1h"},
+		"web": {Duration: "30m"},
+	}}
+
+	entries, err := Presets(cfg, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 presets, got %d", len(entries))
+	}
+
+	db, err := GetPreset(cfg, sub, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Preset.Duration != "1h" || db.Source != "user" || db.Shadows != filepath.Join(root, ProjectFile) {
+		t.Errorf("expected user preset to win over the project's, got %+v", db)
+	}
+
+	ops, err := GetPreset(cfg, sub, "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops.Preset.Duration != "4h" || ops.Source != filepath.Join(root, ProjectFile) || ops.Shadows != "" {
+		t.Errorf("unexpected project preset: %+v", ops)
+	}
+
+	if _, err := GetPreset(cfg, sub, "missing"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestRenderReason(t *testing.T) {
+	p := Preset{Reason: "db maintenance ({{.Ticket}}): {{.Reason}}", RequireReason: true}
+
+	got, err := p.RenderReason(ReasonData{Reason: "reindex", Ticket: "OPS-7", Preset: "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "db maintenance (OPS-7): reindex" {
+		t.Errorf("unexpected reason: %q", got)
+	}
+
+	if _, err := p.RenderReason(ReasonData{Preset: "db"}); err == nil {
+		t.Error("expected error when a required reason is missing")
+	}
+
+	plain := Preset{}
+	if got, _ := plain.RenderReason(ReasonData{Reason: "as typed"}); got != "as typed" {
+		t.Errorf("expected reason to pass through, got %q", got)
+	}
+}
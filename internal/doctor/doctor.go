@@ -0,0 +1,394 @@
+// Package doctor implements the checks behind `eg doctor`. Each check
+// returns a Result with a remediation hint when something is wrong.
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+)
+
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+	Skip
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "pass"
+	case Warn:
+		return "warn"
+	case Fail:
+		return "fail"
+	default:
+		return "skip"
+	}
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// Clock skew thresholds. Session expiry is computed server-side, but a skewed
+// clock makes remaining times and --until wrong, and breaks TLS well before
+// the fail threshold is reached.
+const (
+	SkewWarn = 30 * time.Second
+	SkewFail = 5 * time.Minute
+)
+
+// CheckConfigFile checks that the config file exists and is not readable by
+// other users, since it holds API keys.
+func CheckConfigFile(path string) Result {
+	r := Result{Name: "Config file"}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		r.Status, r.Detail = Fail, path+" does not exist"
+		r.Hint = "Create a profile with: eg profile add <name>"
+		return r
+	}
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		return r
+	}
+
+	r.Detail = path
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		r.Status = Warn
+		r.Detail = fmt.Sprintf("%s is accessible by other users (mode %04o)", path, info.Mode().Perm())
+		r.Hint = "It contains API keys. Run: chmod 600 " + path
+	}
+	return r
+}
+
+// CheckProfile validates a profile's settings without contacting the API.
+func CheckProfile(name string, p *config.Profile) Result {
+	r := Result{Name: fmt.Sprintf("Profile %q", name)}
+	if p.APIKey == "" && !p.UsesLogin() {
+		r.Status, r.Detail = Fail, "profile has neither an api_key nor a login"
+		r.Hint = "Run: eg login --profile " + name
+		return r
+	}
+	u, err := url.Parse(p.APIURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		r.Status, r.Detail = Fail, fmt.Sprintf("api_url %q is not a valid http(s) URL", p.APIURL)
+		r.Hint = "Set api_url to e.g. https://app.entryguard.io/api/v1 in the config file"
+		return r
+	}
+	if p.ReasonPattern != "" {
+		if _, err := regexp.Compile(p.ReasonPattern); err != nil {
+			r.Status, r.Detail = Fail, fmt.Sprintf("reason_pattern is not a valid regular expression: %v", err)
+			r.Hint = "Fix or remove reason_pattern in the config file"
+			return r
+		}
+	}
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		r.Status, r.Detail = Warn, "api_url uses plain HTTP; the API key is sent unencrypted"
+		r.Hint = "Use an https:// api_url"
+		return r
+	}
+	r.Detail = p.APIURL
+	return r
+}
+
+// CheckTransport validates the profile's connection settings (CA file,
+// client certificate, proxy) and returns the transport they produce.
+func CheckTransport(t api.TransportConfig) (Result, *http.Transport) {
+	r := Result{Name: "Connection"}
+	tr, err := t.Transport()
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Hint = "Fix ca_file, client_cert, client_key or proxy_url in the config file"
+		return r, nil
+	}
+
+	var parts []string
+	if t.CAFile != "" {
+		parts = append(parts, "extra CAs from "+t.CAFile)
+	}
+	if t.ClientCert != "" {
+		parts = append(parts, "client certificate "+t.ClientCert)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "system trust store")
+	}
+	r.Detail = strings.Join(parts, ", ")
+
+	if t.InsecureSkipVerify {
+		r.Status = Warn
+		r.Detail += "; certificate verification DISABLED"
+		r.Hint = "Remove insecure_skip_verify and set ca_file to your server's CA bundle"
+	}
+	return r, tr
+}
+
+// CheckProxy reports whether requests to target go through a proxy, taken
+// from the profile's proxy_url or from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+// It returns the proxy in use, if any.
+func CheckProxy(target *url.URL, tr *http.Transport, explicit bool) (Result, *url.URL) {
+	r := Result{Name: "Proxy"}
+	if tr.Proxy == nil {
+		r.Detail = "disabled by proxy_url = \"direct\""
+		return r, nil
+	}
+	proxy, err := tr.Proxy(&http.Request{URL: target})
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("invalid proxy setting: %v", err)
+		r.Hint = "Fix or unset HTTPS_PROXY / HTTP_PROXY"
+		return r, nil
+	}
+	if proxy == nil {
+		r.Detail = "not used for " + target.Host
+		if v := proxyEnv(); v != "" {
+			r.Detail += " (" + v + " set)"
+		}
+		return r, nil
+	}
+	source := "from the environment"
+	if explicit {
+		source = "from proxy_url"
+	}
+	r.Detail = fmt.Sprintf("requests to %s go through %s (%s)", target.Host, redact(proxy), source)
+	return r, proxy
+}
+
+// CheckDNS resolves host.
+func CheckDNS(ctx context.Context, host string) Result {
+	r := Result{Name: "DNS"}
+	if net.ParseIP(host) != nil {
+		r.Status, r.Detail = Skip, host+" is an IP address"
+		return r
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("cannot resolve %s: %v", host, err)
+		r.Hint = "Check your network connection and DNS settings (e.g. /etc/resolv.conf, VPN split DNS)"
+		return r
+	}
+	r.Detail = fmt.Sprintf("%s → %s", host, strings.Join(addrs, ", "))
+	return r
+}
+
+// CheckTLS performs a TLS handshake with the API host using tlsCfg and
+// inspects the server certificate.
+func CheckTLS(ctx context.Context, u *url.URL, tlsCfg *tls.Config, now time.Time) Result {
+	r := Result{Name: "TLS"}
+	if u.Scheme != "https" {
+		r.Status, r.Detail = Skip, "api_url does not use https"
+		return r
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+	cfg := tlsCfg.Clone()
+	cfg.ServerName = u.Hostname()
+	d := &tls.Dialer{Config: cfg}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("handshake with %s failed: %v", addr, err)
+		r.Hint = "If the server uses a private CA, or a proxy intercepts TLS, set ca_file in the profile to that CA bundle"
+		return r
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	r.Detail = tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		left := cert.NotAfter.Sub(now)
+		r.Detail += fmt.Sprintf(", certificate valid until %s", cert.NotAfter.Format("2006-01-02"))
+		if left < 14*24*time.Hour {
+			r.Status = Warn
+			r.Hint = "The server certificate expires soon; report this to your EntryGuard administrator"
+		}
+	}
+	return r
+}
+
+// CheckClock compares the local clock with the Date header returned by the
+// API server.
+func CheckClock(ctx context.Context, client *http.Client, apiURL string, now func() time.Time) Result {
+	r := Result{Name: "Clock"}
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		return r
+	}
+	sent := now()
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Status, r.Detail = Fail, fmt.Sprintf("cannot reach %s: %v", apiURL, err)
+		r.Hint = "Check network access to the API; see the DNS, TLS and Proxy checks"
+		return r
+	}
+	resp.Body.Close()
+	received := now()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		r.Status, r.Detail = Skip, "server did not send a Date header"
+		return r
+	}
+	// The Date header has one-second resolution; compare against the
+	// midpoint of the request.
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := local.Sub(date).Round(time.Second)
+
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs <= time.Second:
+		r.Detail = "in sync with the server"
+	case skew > 0:
+		r.Detail = fmt.Sprintf("local clock is %s ahead of the server", abs)
+	default:
+		r.Detail = fmt.Sprintf("local clock is %s behind the server", abs)
+	}
+	if abs > SkewFail {
+		r.Status = Fail
+	} else if abs > SkewWarn {
+		r.Status = Warn
+	}
+	if r.Status != Pass {
+		r.Hint = "Enable time synchronisation (NTP), e.g.: sudo timedatectl set-ntp true"
+	}
+	return r
+}
+
+// CheckAPIKey verifies the credentials by fetching the current user. login
+// is set for profiles using `eg login` tokens rather than an API key.
+func CheckAPIKey(client *api.Client, login bool) Result {
+	r := Result{Name: "API key"}
+	if login {
+		r.Name = "Login"
+	}
+	user, err := client.GetMe()
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		r.Hint = "The key may be revoked or expired. Create a new one in the web UI and run: eg profile add <name>"
+		if login {
+			r.Hint = "The login may have been revoked. Run: eg login"
+		}
+		return r
+	}
+	r.Detail = fmt.Sprintf("%s in %s", user.Email, user.OrganizationName)
+	if user.IsOrgAdmin {
+		r.Detail += " (admin)"
+	}
+	return r
+}
+
+// CheckIPProvider checks that the IP detection endpoint at rawURL is
+// reachable over network ("tcp4" or "tcp6") with the given transport. IPv6
+// being unavailable is common and only a warning. When the transport sends
+// the request through a proxy, the dial reaches the proxy rather than the
+// provider and says nothing about the address family, so the check is
+// skipped.
+func CheckIPProvider(ctx context.Context, name, rawURL, network string, base *http.Transport) Result {
+	r := Result{Name: name}
+	dialer := &net.Dialer{}
+	tr := base.Clone()
+	tr.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	client := &http.Client{Transport: tr}
+
+	failStatus, hint := Fail, "Without an IPv4 address sessions cannot whitelist you; check outbound HTTPS access to "+hostOf(rawURL)
+	if network == "tcp6" {
+		failStatus, hint = Warn, "No IPv6 connectivity; sessions will only whitelist IPv4. Pass --ipv6 explicitly if you do have an address"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		r.Status, r.Detail = Fail, err.Error()
+		return r
+	}
+	if tr.Proxy != nil {
+		if proxy, err := tr.Proxy(req); err == nil && proxy != nil {
+			r.Status, r.Detail = Skip, hostOf(rawURL)+" is reached via proxy "+proxy.Host
+			return r
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Status, r.Detail, r.Hint = failStatus, fmt.Sprintf("%s unreachable: %v", hostOf(rawURL), err), hint
+		return r
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IP string `json:"ip"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&body) != nil || body.IP == "" {
+		r.Status, r.Detail, r.Hint = failStatus, fmt.Sprintf("%s returned an unexpected response (HTTP %d)", hostOf(rawURL), resp.StatusCode), hint
+		return r
+	}
+	r.Detail = fmt.Sprintf("%s via %s", body.IP, hostOf(rawURL))
+	return r
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return rawURL
+}
+
+// proxyEnv lists the names of the proxy variables that are set. Values are
+// left out as they may carry credentials.
+func proxyEnv() string {
+	var set []string
+	for _, k := range []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"} {
+		if os.Getenv(k) != "" || os.Getenv(strings.ToLower(k)) != "" {
+			set = append(set, k)
+		}
+	}
+	return strings.Join(set, ", ")
+}
+
+// redact hides proxy credentials.
+func redact(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	c := *u
+	c.User = url.User("***")
+	return c.String()
+}
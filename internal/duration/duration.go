@@ -0,0 +1,141 @@
+// Package duration parses the human-friendly session lengths accepted by
+// --duration, --for and --until.
+package duration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// Parse accepts Go-style durations ("45m", "1h30m"), a "d" suffix for days
+// ("1d12h") and, for backwards compatibility, a bare integer meaning hours.
+func Parse(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if n <= 0 {
+			return 0, fmt.Errorf("duration must be positive: %s", s)
+		}
+		return time.Duration(n) * time.Hour, nil
+	}
+
+	// Only a leading sign is allowed; "1d-12h" would otherwise subtract.
+	if strings.ContainsAny(strings.TrimPrefix(s, "+"), "+-") {
+		return 0, fmt.Errorf("invalid duration %q (examples: 45m, 1h30m, 2d)", s)
+	}
+
+	var days time.Duration
+	if i := strings.Index(s, "d"); i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days = time.Duration(n) * day
+		s = s[i+1:]
+	}
+
+	var rest time.Duration
+	if s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q (examples: 45m, 1h30m, 2d)", s)
+		}
+		rest = d
+	}
+
+	total := days + rest
+	if total <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return total, nil
+}
+
+// Until returns the time remaining from now until the given wall-clock time
+// ("18:00", "18:00:30") or RFC 3339 timestamp. A clock time that has already
+// passed today refers to tomorrow.
+func Until(s string, now time.Time) (time.Duration, error) {
+	target, err := ParseClock(s, now)
+	if err != nil {
+		return 0, err
+	}
+	d := target.Sub(now)
+	if d <= 0 {
+		return 0, fmt.Errorf("%s is in the past", s)
+	}
+	return d, nil
+}
+
+// Extension returns how much to add to a session expiring at expiry so it
+// ends at s, a wall-clock time or RFC 3339 timestamp. A clock time refers to
+// its next occurrence after the expiry, in loc, so "18:00" extends a session
+// ending tomorrow morning to tomorrow evening. The result is not positive if
+// s is not after the expiry.
+func Extension(expiry time.Time, s string, loc *time.Location) (time.Duration, error) {
+	target, err := ParseClock(s, expiry.In(loc))
+	if err != nil {
+		return 0, err
+	}
+	return target.Sub(expiry), nil
+}
+
+// ParseClock resolves a wall-clock time or RFC 3339 timestamp to an absolute
+// time, using now's location and picking the next occurrence of clock times.
+func ParseClock(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		c, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		t := time.Date(now.Year(), now.Month(), now.Day(), c.Hour(), c.Minute(), c.Second(), 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected HH:MM or an RFC 3339 timestamp)", s)
+}
+
+// Split maps a duration onto the API's whole-hour and whole-minute fields.
+// Durations that are an exact number of hours are sent as hours, which every
+// server version understands; anything else is rounded UP to the next whole
+// minute so the session never ends earlier than requested.
+func Split(d time.Duration) (hours, minutes int) {
+	if d%time.Hour == 0 {
+		return int(d / time.Hour), 0
+	}
+	minutes = int(d / time.Minute)
+	if d%time.Minute != 0 {
+		minutes++
+	}
+	return 0, minutes
+}
+
+// ParseSince resolves a --since value to an absolute time. It accepts a
+// look-back duration ("30d", "12h"), a date ("2024-05-01", local midnight) or
+// an RFC 3339 timestamp.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	d, err := Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (examples: 30d, 12h, 2024-05-01)", s)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,140 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"2", 2 * time.Hour},
+		{"45m", 45 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1d", 24 * time.Hour},
+		{"2d6h", 54 * time.Hour},
+		{" 90s ", 90 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, in := range []string{"", "0", "-1", "abc", "1x", "d", "-5m", "0h", "1d-12h", "2d-47h", "-1d48h", "1h-30m", "1d+2h"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): expected error", in)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	d, err := Until("18:00", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 8*time.Hour {
+		t.Errorf("expected 8h, got %s", d)
+	}
+
+	// A time already passed today rolls over to tomorrow
+	d, err = Until("09:30", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 23*time.Hour+30*time.Minute {
+		t.Errorf("expected 23h30m, got %s", d)
+	}
+
+	if _, err := Until("2024-05-01T09:00:00Z", now); err == nil {
+		t.Error("expected error for timestamp in the past")
+	}
+	if _, err := Until("25:00", now); err == nil {
+		t.Error("expected error for invalid clock time")
+	}
+}
+
+func TestExtension(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// Expires tomorrow 10:00 local time, as the API reports it (UTC).
+	expiry := time.Date(2024, 5, 2, 10, 0, 0, 0, loc).UTC()
+
+	d, err := Extension(expiry, "18:00", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 8*time.Hour {
+		t.Errorf("18:00: expected 8h, got %s", d)
+	}
+
+	// Earlier clock times roll over to the day after the expiry.
+	if d, _ := Extension(expiry, "09:00", loc); d != 23*time.Hour {
+		t.Errorf("09:00: expected 23h, got %s", d)
+	}
+
+	// Timestamps are taken as-is.
+	if d, _ := Extension(expiry, "2024-05-02T09:00:00+02:00", loc); d > 0 {
+		t.Errorf("timestamp before expiry: expected a non-positive extension, got %s", d)
+	}
+	if _, err := Extension(expiry, "teatime", loc); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in          time.Duration
+		hours, mins int
+	}{
+		{2 * time.Hour, 2, 0},
+		{45 * time.Minute, 0, 45},
+		{90 * time.Minute, 0, 90},
+		{30*time.Minute + time.Second, 0, 31},
+		{10 * time.Second, 0, 1},
+	}
+	for _, tt := range tests {
+		h, m := Split(tt.in)
+		if h != tt.hours || m != tt.mins {
+			t.Errorf("Split(%s) = (%d, %d), want (%d, %d)", tt.in, h, m, tt.hours, tt.mins)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30d", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"12h", time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01T08:00:00Z", time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseSince("last month", now); err == nil {
+		t.Error("expected error for unparseable value")
+	}
+}
@@ -0,0 +1,145 @@
+// Package hooks runs the local actions configured for session lifecycle
+// events in a profile's [hooks] table.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/lifecycle"
+)
+
+// Timeout bounds each hook.
+const Timeout = 30 * time.Second
+
+// Payload is what a hook receives: on stdin for commands, as the request
+// body for URLs.
+type Payload struct {
+	Event   lifecycle.Event `json:"event"`
+	Profile string          `json:"profile"`
+	Session *api.Session    `json:"session"`
+}
+
+// Output receives the stdout and stderr of hook commands. It defaults to
+// stderr so hooks never mix with a command's own output.
+var Output io.Writer = os.Stderr
+
+// For returns the hooks configured for an event. on_session_start also runs
+// when a session is extended, since its expiry changed.
+func For(h *config.Hooks, e lifecycle.Event) []string {
+	switch e {
+	case lifecycle.Started, lifecycle.Extended:
+		return h.OnSessionStart
+	case lifecycle.Expiring:
+		return h.OnSessionExpiring
+	case lifecycle.Ended:
+		return h.OnSessionEnd
+	}
+	return nil
+}
+
+// defaultClient posts URL hooks when the caller has no client of its own.
+var defaultClient = &http.Client{Timeout: Timeout}
+
+// Run runs each hook in order. URL hooks are posted with client, normally
+// one using the profile's connection settings; nil uses a plain client. A
+// failing hook does not stop the others; the failures are joined in the
+// returned error.
+func Run(ctx context.Context, client *http.Client, hooks []string, p Payload) error {
+	if client == nil {
+		client = defaultClient
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	var errs []error
+	for _, hook := range hooks {
+		hook = strings.TrimSpace(hook)
+		if hook == "" {
+			continue
+		}
+		hctx, cancel := context.WithTimeout(ctx, Timeout)
+		if isURL(hook) {
+			err = post(hctx, client, hook, body)
+		} else {
+			err = command(hctx, hook, body, env(p))
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", hook, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isURL(hook string) bool {
+	return strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://")
+}
+
+func post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func command(ctx context.Context, line string, body []byte, env []string) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", line)
+	} else {
+		c = exec.CommandContext(ctx, "/bin/sh", "-c", line)
+	}
+	c.Stdin = bytes.NewReader(body)
+	c.Stdout = Output
+	c.Stderr = Output
+	c.Env = append(os.Environ(), env...)
+	return c.Run()
+}
+
+// env exposes the most useful payload fields to commands, so simple hooks
+// need no JSON parsing.
+func env(p Payload) []string {
+	vars := []string{
+		"EG_EVENT=" + string(p.Event),
+		"EG_PROFILE=" + p.Profile,
+	}
+	if s := p.Session; s != nil {
+		vars = append(vars,
+			"EG_SESSION_ID="+s.ID,
+			"EG_SESSION_STATUS="+s.Status,
+			"EG_SESSION_EXPIRES_AT="+s.ExpiresAt,
+			"EG_SESSION_IPV4="+s.Ipv4Address,
+			"EG_SESSION_IPV6="+s.Ipv6Address,
+		)
+	}
+	return vars
+}
@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entryguard-io/cli/internal/api"
+	"github.com/entryguard-io/cli/internal/config"
+	"github.com/entryguard-io/cli/internal/lifecycle"
+)
+
+func TestFor(t *testing.T) {
+	h := &config.Hooks{OnSessionStart: []string{"s"}, OnSessionExpiring: []string{"x"}, OnSessionEnd: []string{"e"}}
+	for event, want := range map[lifecycle.Event]string{
+		lifecycle.Started:  "s",
+		lifecycle.Extended: "s",
+		lifecycle.Expiring: "x",
+		lifecycle.Ended:    "e",
+	} {
+		if got := For(h, event); len(got) != 1 || got[0] != want {
+			t.Errorf("For(%s) = %v, want [%s]", event, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	var posted Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "out")
+	p := Payload{
+		Event:   lifecycle.Started,
+		Profile: "prod",
+		Session: &api.Session{ID: "s-1", Status: "ACTIVE"},
+	}
+	err := Run(context.Background(), nil, []string{
+		`echo "$EG_EVENT $EG_PROFILE $EG_SESSION_ID" > ` + out + `; cat >> ` + out,
+		srv.URL,
+		"exit 3",
+	}, p)
+
+	// The failing hook is reported, the others still ran.
+	if err == nil || !strings.Contains(err.Error(), `hook "exit 3"`) {
+		t.Errorf("err = %v, want the failing hook", err)
+	}
+	data, _ := os.ReadFile(out)
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "session.started prod s-1" {
+		t.Errorf("env line = %q", lines[0])
+	}
+	var stdin Payload
+	if len(lines) < 2 || json.Unmarshal([]byte(lines[1]), &stdin) != nil || stdin.Session.ID != "s-1" {
+		t.Errorf("stdin = %q", data)
+	}
+	if posted.Event != lifecycle.Started || posted.Session == nil || posted.Session.ID != "s-1" {
+		t.Errorf("posted = %+v", posted)
+	}
+}
+
+func TestRunPostsWithClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	p := Payload{Event: lifecycle.Ended, Profile: "prod"}
+
+	// The server's certificate is only trusted by its own client.
+	if err := Run(context.Background(), nil, []string{srv.URL}, p); err == nil {
+		t.Error("expected the default client to reject the test certificate")
+	}
+	if err := Run(context.Background(), srv.Client(), []string{srv.URL}, p); err != nil {
+		t.Errorf("posting with the given client: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Package lifecycle derives session lifecycle events from successive
+// session listings, for commands that watch sessions over time.
+package lifecycle
+
+import (
+	"sort"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+)
+
+// Event names a point in a session's life.
+type Event string
+
+const (
+	Started  Event = "session.started"
+	Extended Event = "session.extended"
+	Expiring Event = "session.expiring"
+	Ended    Event = "session.ended"
+)
+
+// Change is an event for one session.
+type Change struct {
+	Event   Event
+	Session api.Session
+}
+
+// Tracker turns successive listings of a user's sessions into events. It
+// only remembers sessions that are live (active, partial or pending).
+type Tracker struct {
+	// Before is how long before expiry a session becomes Expiring. Each
+	// expiry time is reported once; extending the session re-arms it.
+	Before time.Duration
+
+	sessions map[string]*tracked
+	primed   bool
+}
+
+type tracked struct {
+	session  api.Session
+	warnedAt string // ExpiresAt when Expiring was reported
+}
+
+func NewTracker(before time.Duration) *Tracker {
+	return &Tracker{Before: before, sessions: make(map[string]*tracked)}
+}
+
+// Update compares sessions with the previous listing and returns what
+// changed, in listing order with ended sessions last. The first call only
+// establishes a baseline: sessions already live are not reported as
+// Started, though they may be reported as Expiring.
+func (t *Tracker) Update(sessions []api.Session, now time.Time) []Change {
+	var changes []Change
+	live := make(map[string]bool)
+	listed := make(map[string]api.Session)
+
+	for _, s := range sessions {
+		listed[s.ID] = s
+		if !isLive(&s) {
+			continue
+		}
+		live[s.ID] = true
+
+		tr, ok := t.sessions[s.ID]
+		switch {
+		case !ok:
+			tr = &tracked{}
+			t.sessions[s.ID] = tr
+			if t.primed {
+				changes = append(changes, Change{Started, s})
+			}
+		case s.ExpiresAt != tr.session.ExpiresAt && later(s.ExpiresAt, tr.session.ExpiresAt):
+			changes = append(changes, Change{Extended, s})
+		}
+		tr.session = s
+
+		if d, ok := s.Remaining(now); ok && d > 0 && d <= t.Before && tr.warnedAt != s.ExpiresAt {
+			tr.warnedAt = s.ExpiresAt
+			changes = append(changes, Change{Expiring, s})
+		}
+	}
+
+	var ended []string
+	for id := range t.sessions {
+		if !live[id] {
+			ended = append(ended, id)
+		}
+	}
+	sort.Strings(ended)
+	for _, id := range ended {
+		s := t.sessions[id].session
+		if final, ok := listed[id]; ok {
+			s = final
+		}
+		changes = append(changes, Change{Ended, s})
+		delete(t.sessions, id)
+	}
+
+	t.primed = true
+	return changes
+}
+
+func isLive(s *api.Session) bool {
+	return s.IsActive() || s.Status == "PENDING"
+}
+
+// later reports whether timestamp a is after b. Unparseable timestamps are
+// never later.
+func later(a, b string) bool {
+	ta, err := time.Parse(time.RFC3339Nano, a)
+	if err != nil {
+		return false
+	}
+	tb, err := time.Parse(time.RFC3339Nano, b)
+	return err != nil || ta.After(tb)
+}
@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+)
+
+func session(id, status string, expires time.Time) api.Session {
+	return api.Session{ID: id, Status: status, ExpiresAt: expires.Format(time.RFC3339)}
+}
+
+func events(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		out = append(out, string(c.Event)+" "+c.Session.ID)
+	}
+	return out
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(10 * time.Minute)
+
+	steps := []struct {
+		name     string
+		at       time.Duration
+		sessions []api.Session
+		want     []string
+	}{
+		{
+			name:     "baseline reports only expiring",
+			sessions: []api.Session{session("a", "ACTIVE", now.Add(time.Hour)), session("b", "ACTIVE", now.Add(5*time.Minute))},
+			want:     []string{"session.expiring b"},
+		},
+		{
+			name:     "new session starts, expiring is not repeated",
+			at:       time.Minute,
+			sessions: []api.Session{session("a", "ACTIVE", now.Add(time.Hour)), session("b", "ACTIVE", now.Add(5*time.Minute)), session("c", "PENDING", now.Add(time.Hour))},
+			want:     []string{"session.started c"},
+		},
+		{
+			name:     "extension re-arms expiring",
+			at:       2 * time.Minute,
+			sessions: []api.Session{session("a", "ACTIVE", now.Add(time.Hour)), session("b", "ACTIVE", now.Add(8*time.Minute)), session("c", "ACTIVE", now.Add(time.Hour))},
+			want:     []string{"session.extended b", "session.expiring b"},
+		},
+		{
+			name:     "missing and inactive sessions end",
+			at:       3 * time.Minute,
+			sessions: []api.Session{session("a", "CANCELLED", now.Add(time.Hour))},
+			want:     []string{"session.ended a", "session.ended b", "session.ended c"},
+		},
+		{
+			name: "nothing left",
+			at:   4 * time.Minute,
+		},
+	}
+
+	for _, step := range steps {
+		got := events(tr.Update(step.sessions, now.Add(step.at)))
+		if len(got) != len(step.want) {
+			t.Fatalf("%s: got %v, want %v", step.name, got, step.want)
+		}
+		for i := range got {
+			if got[i] != step.want[i] {
+				t.Fatalf("%s: got %v, want %v", step.name, got, step.want)
+			}
+		}
+	}
+}
+
+func TestTrackerEndedKeepsFinalState(t *testing.T) {
+	now := time.Now()
+	tr := NewTracker(time.Minute)
+	tr.Update([]api.Session{session("a", "ACTIVE", now.Add(time.Hour))}, now)
+
+	changes := tr.Update([]api.Session{session("a", "EXPIRED", now)}, now)
+	if len(changes) != 1 || changes[0].Event != Ended || changes[0].Session.Status != "EXPIRED" {
+		t.Errorf("got %+v", changes)
+	}
+}
@@ -0,0 +1,49 @@
+// Package notify raises desktop notifications, falling back to the terminal
+// where the desktop offers no way to do so.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AppName is the application name notifications are sent under.
+const AppName = "EntryGuard"
+
+// ErrUnsupported is returned by Send when the platform has no notification
+// mechanism eg knows how to use.
+var ErrUnsupported = errors.New("desktop notifications are not supported on this system")
+
+// Notification is a desktop notification. Urgent ones stay on screen until
+// dismissed where the desktop supports it.
+type Notification struct {
+	Title  string
+	Body   string
+	Urgent bool
+}
+
+// Send shows n as a desktop notification.
+func Send(n Notification) error {
+	return send(n)
+}
+
+// Available reports whether the system has a notification mechanism Send
+// can use, without showing anything. It only checks that the tools are
+// installed; Send may still fail, e.g. without a session bus.
+func Available() error {
+	return available()
+}
+
+// Terminal writes n to w, prefixed with a terminal bell. It is the fallback
+// when Send fails.
+func Terminal(w io.Writer, n Notification) {
+	fmt.Fprintf(w, "\a%s: %s\n", n.Title, strings.ReplaceAll(n.Body, "\n", " "))
+}
+
+// gvariantString quotes s as a GVariant text-format string for gdbus.
+func gvariantString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`)
+	return "'" + r.Replace(s) + "'"
+}
@@ -0,0 +1,28 @@
+//go:build darwin
+
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// send uses AppleScript's display notification through osascript.
+func send(n Notification) error {
+	script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(n.Body), strconv.Quote(AppName))
+	if n.Title != "" {
+		script += " subtitle " + strconv.Quote(n.Title)
+	}
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript: %v: %s", err, out)
+	}
+	return nil
+}
+
+func available() error {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return nil
+}
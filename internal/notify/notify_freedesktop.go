@@ -0,0 +1,66 @@
+//go:build linux || freebsd || netbsd || openbsd || dragonfly
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// send calls org.freedesktop.Notifications over the session bus with gdbus,
+// which ships with GLib on practically every desktop, and falls back to
+// notify-send.
+func send(n Notification) error {
+	if path, err := exec.LookPath("gdbus"); err == nil {
+		urgency := 1
+		if n.Urgent {
+			urgency = 2
+		}
+		out, err := exec.Command(path, "call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			gvariantString(AppName), "uint32 0", gvariantString("dialog-warning"),
+			gvariantString(n.Title), gvariantString(n.Body),
+			"@as []", fmt.Sprintf("{'urgency': <byte %d>}", urgency), "int32 -1",
+		).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		if _, lookErr := exec.LookPath("notify-send"); lookErr != nil {
+			return fmt.Errorf("gdbus: %s", firstLine(out, err))
+		}
+	}
+
+	if path, err := exec.LookPath("notify-send"); err == nil {
+		urgency := "normal"
+		if n.Urgent {
+			urgency = "critical"
+		}
+		out, err := exec.Command(path, "--app-name", AppName, "--urgency", urgency, "--icon", "dialog-warning", n.Title, n.Body).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("notify-send: %s", firstLine(out, err))
+		}
+		return nil
+	}
+	return errors.Join(ErrUnsupported, errors.New("neither gdbus nor notify-send is installed"))
+}
+
+func available() error {
+	for _, tool := range []string{"gdbus", "notify-send"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: neither gdbus nor notify-send is installed", ErrUnsupported)
+}
+
+func firstLine(out []byte, err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if line == "" {
+		return err.Error()
+	}
+	return line
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || freebsd || netbsd || openbsd || dragonfly || darwin)
+
+package notify
+
+func send(n Notification) error {
+	return ErrUnsupported
+}
+
+func available() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGVariantString(t *testing.T) {
+	tests := map[string]string{
+		"plain":      `'plain'`,
+		"it's":       `'it\'s'`,
+		`back\slash`: `'back\\slash'`,
+		"two\nlines": `'two\nlines'`,
+		"":           `''`,
+	}
+	for in, want := range tests {
+		if got := gvariantString(in); got != want {
+			t.Errorf("gvariantString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	Terminal(&buf, Notification{Title: "Session expiring", Body: "in 5m\nExtend with: eg session extend"})
+	if got, want := buf.String(), "\aSession expiring: in 5m Extend with: eg session extend\n"; got != want {
+		t.Errorf("Terminal wrote %q, want %q", got, want)
+	}
+}
+
+func TestAvailableWithoutTools(t *testing.T) {
+	t.Setenv("PATH", "")
+	if err := Available(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Available() = %v, want ErrUnsupported", err)
+	}
+}
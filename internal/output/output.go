@@ -50,13 +50,13 @@ func PrintTable(headers []string, rows [][]string) {
 
 func StatusColor(status string) string {
 	switch strings.ToUpper(status) {
-	case "ACTIVE":
+	case "ACTIVE", "ONLINE":
 		return color.GreenString(status)
 	case "PENDING":
 		return color.YellowString(status)
 	case "EXPIRED", "CANCELLED":
 		return color.HiBlackString(status)
-	case "FAILED":
+	case "FAILED", "OFFLINE":
 		return color.RedString(status)
 	case "EXPIRING":
 		return color.YellowString(status)
@@ -100,12 +100,27 @@ func FormatDuration(expiresAt string) string {
 	if remaining < 0 {
 		return "expired"
 	}
-	hours := int(remaining.Hours())
-	minutes := int(remaining.Minutes()) % 60
-	if hours > 0 {
+	return FormatSpan(remaining)
+}
+
+// FormatSpan renders a duration compactly: "2d 3h", "1h 5m", "12m" or "40s".
+func FormatSpan(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
 		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
 	}
-	return fmt.Sprintf("%dm", minutes)
 }
 
 func Success(msg string, args ...any) {
@@ -122,3 +137,12 @@ func Info(msg string, args ...any) {
 	blue := color.New(color.FgBlue).SprintFunc()
 	fmt.Printf("%s %s\n", blue("→"), fmt.Sprintf(msg, args...))
 }
+
+// ShortID returns the first 8 characters of an ID for display. IDs shorter
+// than that are returned unchanged.
+func ShortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
@@ -0,0 +1,150 @@
+// Package report aggregates session history into usage totals.
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+)
+
+// Entry is the aggregated usage for one user, IP or resource.
+type Entry struct {
+	Key      string        `json:"key"`
+	Sessions int           `json:"sessions"`
+	Total    time.Duration `json:"-"`
+	Seconds  int64         `json:"totalSeconds"`
+	LastSeen time.Time     `json:"lastSeen"`
+}
+
+// Report holds usage totals grouped three ways, each sorted by total
+// whitelisted time, longest first. Sessions counts the sessions that
+// contributed to the totals.
+type Report struct {
+	Since     time.Time `json:"since"`
+	Sessions  int       `json:"sessions"`
+	Users     []Entry   `json:"users,omitempty"`
+	IPs       []Entry   `json:"ips"`
+	Resources []Entry   `json:"resources"`
+}
+
+// Build aggregates sessions into a report. Sessions still running are
+// counted up to now, and only the part of a session after since counts.
+func Build(sessions []api.Session, since, now time.Time) *Report {
+	users := make(map[string]*Entry)
+	ips := make(map[string]*Entry)
+	resources := make(map[string]*Entry)
+	counted := 0
+
+	for _, s := range sessions {
+		start, span := Span(s, now)
+		if start.Before(since) {
+			span -= since.Sub(start)
+		}
+		if span <= 0 {
+			continue
+		}
+		counted++
+
+		add(users, userKey(s), start, span)
+		if s.Ipv4Address != "" {
+			add(ips, s.Ipv4Address, start, span)
+		}
+		if s.Ipv6Address != "" {
+			add(ips, s.Ipv6Address, start, span)
+		}
+
+		seen := make(map[string]bool)
+		for _, r := range s.ResourceIps {
+			if r.ResourceName == "" || seen[r.ResourceName] {
+				continue
+			}
+			seen[r.ResourceName] = true
+			add(resources, r.ResourceName, start, span)
+		}
+	}
+
+	return &Report{
+		Since:     since,
+		Sessions:  counted,
+		Users:     sorted(users),
+		IPs:       sorted(ips),
+		Resources: sorted(resources),
+	}
+}
+
+// Span returns when a session started and how long it was whitelisted: until
+// it ended, until it expires, or until now if it is still running.
+func Span(s api.Session, now time.Time) (time.Time, time.Duration) {
+	start, ok := parseTime(s.StartedAt)
+	if !ok {
+		return time.Time{}, 0
+	}
+	end := now
+	if t, ok := parseTime(s.EndedAt); ok {
+		end = t
+	} else if t, ok := parseTime(s.ExpiresAt); ok && t.Before(now) {
+		end = t
+	}
+	return start, end.Sub(start)
+}
+
+// Top returns at most n entries; n <= 0 returns all of them.
+func Top(entries []Entry, n int) []Entry {
+	if n <= 0 || len(entries) <= n {
+		return entries
+	}
+	return entries[:n]
+}
+
+func userKey(s api.Session) string {
+	switch {
+	case s.UserEmail != "":
+		return s.UserEmail
+	case s.UserName != "":
+		return s.UserName
+	case s.UserID != "":
+		return s.UserID
+	default:
+		return "(unknown)"
+	}
+}
+
+func add(m map[string]*Entry, key string, start time.Time, span time.Duration) {
+	e, ok := m[key]
+	if !ok {
+		e = &Entry{Key: key}
+		m[key] = e
+	}
+	e.Sessions++
+	e.Total += span
+	e.Seconds = int64(e.Total / time.Second)
+	if start.After(e.LastSeen) {
+		e.LastSeen = start
+	}
+}
+
+func sorted(m map[string]*Entry) []Entry {
+	entries := make([]Entry, 0, len(m))
+	for _, e := range m {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Total != entries[j].Total {
+			return entries[i].Total > entries[j].Total
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+func parseTime(ts string) (time.Time, bool) {
+	if ts == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
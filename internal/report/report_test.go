@@ -0,0 +1,106 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/internal/api"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []api.Session{
+		{
+			UserEmail:   "alice@example.com",
+			Ipv4Address: "203.0.113.10",
+			StartedAt:   "2024-05-10T08:00:00Z",
+			EndedAt:     "2024-05-10T10:00:00Z",
+			ResourceIps: []api.SessionResourceIp{
+				{ResourceName: "db-prod", IpVersion: 4},
+				{ResourceName: "db-prod", IpVersion: 6},
+				{ResourceName: "bastion", IpVersion: 4},
+			},
+		},
+		{
+			UserEmail:   "bob@example.com",
+			Ipv4Address: "203.0.113.20",
+			Ipv6Address: "2001:db8::1",
+			StartedAt:   "2024-05-20T09:00:00Z",
+			ExpiresAt:   "2024-05-20T09:30:00Z",
+			ResourceIps: []api.SessionResourceIp{{ResourceName: "db-prod"}},
+		},
+		{
+			// Still running: counted up to now
+			UserEmail:   "alice@example.com",
+			Ipv4Address: "203.0.113.10",
+			StartedAt:   "2024-06-01T11:00:00Z",
+			ExpiresAt:   "2024-06-01T13:00:00Z",
+		},
+	}
+
+	r := Build(sessions, now.AddDate(0, -1, 0), now)
+
+	if len(r.Users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(r.Users))
+	}
+	if r.Users[0].Key != "alice@example.com" || r.Users[0].Total != 3*time.Hour || r.Users[0].Sessions != 2 {
+		t.Errorf("unexpected top user: %+v", r.Users[0])
+	}
+	if r.Users[1].Total != 30*time.Minute {
+		t.Errorf("expected bob to have 30m, got %s", r.Users[1].Total)
+	}
+
+	if len(r.IPs) != 3 {
+		t.Fatalf("expected 3 IPs, got %d", len(r.IPs))
+	}
+
+	if len(r.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(r.Resources))
+	}
+	// db-prod is credited once per session, not once per IP version
+	if r.Resources[0].Key != "db-prod" || r.Resources[0].Total != 150*time.Minute || r.Resources[0].Sessions != 2 {
+		t.Errorf("unexpected top resource: %+v", r.Resources[0])
+	}
+}
+
+func TestBuildClipsToSince(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	sessions := []api.Session{
+		{
+			// Started the evening before: only the time after since counts
+			UserEmail: "alice@example.com",
+			StartedAt: "2024-05-31T20:00:00Z",
+			EndedAt:   "2024-06-01T02:00:00Z",
+		},
+		{
+			// Over before since
+			UserEmail: "bob@example.com",
+			StartedAt: "2024-05-31T08:00:00Z",
+			EndedAt:   "2024-05-31T09:00:00Z",
+		},
+		{
+			// Pending: never started
+			UserEmail: "carol@example.com",
+			Status:    "PENDING",
+		},
+	}
+
+	r := Build(sessions, since, now)
+	if len(r.Users) != 1 || r.Users[0].Key != "alice@example.com" || r.Users[0].Total != 2*time.Hour {
+		t.Errorf("users = %+v, want alice with 2h", r.Users)
+	}
+	if r.Sessions != 1 {
+		t.Errorf("sessions = %d, want only the one counted in the totals", r.Sessions)
+	}
+}
+
+func TestTop(t *testing.T) {
+	entries := []Entry{{Key: "a"}, {Key: "b"}, {Key: "c"}}
+	if got := Top(entries, 2); len(got) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(got))
+	}
+	if got := Top(entries, 0); len(got) != 3 {
+		t.Errorf("expected all entries, got %d", len(got))
+	}
+}
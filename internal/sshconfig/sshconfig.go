@@ -0,0 +1,277 @@
+// Package sshconfig maintains the block of Host entries eg writes into an
+// OpenSSH client config, leaving everything outside the block untouched.
+package sshconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Host is one Host entry of the managed block.
+type Host struct {
+	Alias    string
+	HostName string
+	Port     int
+	User     string
+	// HostKeyAlias, if set, names the host's known_hosts entry instead of
+	// HostName and Port, which may change between sessions.
+	HostKeyAlias string
+	// Comment, if set, is written on the line above the entry.
+	Comment string
+}
+
+// ErrUnterminated is returned when a block's begin marker has no matching
+// end marker; the file is left alone rather than guessing where it ends.
+var ErrUnterminated = errors.New("managed block has no end marker")
+
+func beginMarker(profile string) string { return "# BEGIN eg ssh-config: " + profile }
+func endMarker(profile string) string   { return "# END eg ssh-config: " + profile }
+
+// Block renders hosts as the managed block for profile, markers included.
+// Each profile gets its own block so several can coexist in one file.
+//
+// Values come partly from the API, so each must be a single token: Block
+// fails if any contains whitespace or control characters, which could
+// otherwise smuggle extra directives into the file. Control characters are
+// stripped from comments. Aliases must be unique.
+func Block(profile string, hosts []Host) (string, error) {
+	for _, h := range hosts {
+		for _, f := range []struct{ name, value string }{
+			{"alias", h.Alias},
+			{"host name", h.HostName},
+			{"user", h.User},
+			{"host key alias", h.HostKeyAlias},
+		} {
+			if err := checkToken(f.value); err != nil {
+				return "", fmt.Errorf("invalid %s %q: %w", f.name, f.value, err)
+			}
+		}
+		if h.Alias == "" || h.HostName == "" {
+			return "", fmt.Errorf("host entry needs an alias and a host name")
+		}
+	}
+	aliases := make(map[string]bool)
+	for _, h := range hosts {
+		// ssh would silently use the first of two entries.
+		if aliases[h.Alias] {
+			return "", fmt.Errorf("duplicate host alias %q", h.Alias)
+		}
+		aliases[h.Alias] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(beginMarker(profile) + "\n")
+	b.WriteString("# Generated by eg ssh-config; edits inside this block are overwritten.\n")
+	for _, h := range hosts {
+		b.WriteString("\n")
+		if c := stripControl(h.Comment); c != "" {
+			b.WriteString("# " + c + "\n")
+		}
+		fmt.Fprintf(&b, "Host %s\n", h.Alias)
+		fmt.Fprintf(&b, "    HostName %s\n", h.HostName)
+		fmt.Fprintf(&b, "    Port %d\n", h.Port)
+		if h.User != "" {
+			fmt.Fprintf(&b, "    User %s\n", h.User)
+		}
+		if h.HostKeyAlias != "" {
+			fmt.Fprintf(&b, "    HostKeyAlias %s\n", h.HostKeyAlias)
+		}
+	}
+	b.WriteString(endMarker(profile) + "\n")
+	return b.String(), nil
+}
+
+// checkToken rejects values ssh would not read back as a single argument.
+func checkToken(s string) error {
+	for _, r := range s {
+		switch {
+		case unicode.IsControl(r):
+			return errors.New("contains control characters")
+		case unicode.IsSpace(r):
+			return errors.New("contains whitespace")
+		}
+	}
+	return nil
+}
+
+// stripControl removes control characters, newlines included, so s stays
+// on one comment line.
+func stripControl(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s))
+}
+
+// Update returns config with profile's managed block replaced by block. If
+// the config has no block yet, block is appended; if block is empty, the
+// existing one is removed.
+func Update(config, profile, block string) (string, error) {
+	begin, end := beginMarker(profile), endMarker(profile)
+	var out []string
+	found, inside := false, false
+
+	sc := bufio.NewScanner(strings.NewReader(config))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch trimmed := strings.TrimSpace(line); {
+		case !inside && trimmed == begin:
+			if found {
+				return "", fmt.Errorf("more than one managed block for profile %q", profile)
+			}
+			found, inside = true, true
+			if block != "" {
+				out = append(out, strings.TrimSuffix(block, "\n"))
+			}
+		case inside && trimmed == end:
+			inside = false
+		case !inside:
+			out = append(out, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	if inside {
+		return "", fmt.Errorf("%w (%q)", ErrUnterminated, begin)
+	}
+
+	if block == "" || !found {
+		// Drop the blank line that separated a removed block, or separate
+		// an appended one from whatever precedes it by exactly one.
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			out = out[:len(out)-1]
+		}
+	}
+	if !found && block != "" {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, strings.TrimSuffix(block, "\n"))
+	}
+	if len(out) == 0 {
+		return "", nil
+	}
+	return strings.Join(out, "\n") + "\n", nil
+}
+
+// DefaultPath returns ~/.ssh/config.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ssh", "config"), nil
+}
+
+// UpdateFile applies Update to the file at path, creating it (and its
+// directory) if needed. It reports whether the file changed. The file is
+// replaced atomically; a symlinked config is updated at its target.
+func UpdateFile(path, profile, block string) (bool, error) {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	mode := os.FileMode(0600)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if block == "" {
+			return false, nil
+		}
+	default:
+		return false, err
+	}
+
+	updated, err := Update(string(data), profile, block)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if updated == string(data) {
+		return false, nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return false, err
+	}
+	if _, err := tmp.WriteString(updated); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return false, err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return false, err
+	}
+	return true, os.Rename(tmp.Name(), path)
+}
+
+// Alias turns a resource name into a Host alias: lower case, with anything
+// other than letters, digits, '.', '_' and '-' replaced by '-'.
+func Alias(prefix, name string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		ok := r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-'
+		if !ok {
+			if !dash {
+				b.WriteByte('-')
+			}
+			dash = true
+			continue
+		}
+		b.WriteRune(r)
+		dash = false
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// Aliases returns an alias for each resource, keyed by resource ID, given
+// their names keyed the same way. Names that Alias maps to the same alias,
+// such as "DB Prod" and "db-prod", get the start of their ID appended so
+// each resource keeps a Host entry of its own.
+func Aliases(prefix string, names map[string]string) map[string]string {
+	byAlias := make(map[string][]string)
+	for id, name := range names {
+		a := Alias(prefix, name)
+		byAlias[a] = append(byAlias[a], id)
+	}
+
+	out := make(map[string]string, len(names))
+	for a, ids := range byAlias {
+		for _, id := range ids {
+			if len(ids) > 1 {
+				short := id
+				if len(short) > 8 {
+					short = short[:8]
+				}
+				out[id] = a + "-" + Alias("", short)
+				continue
+			}
+			out[id] = a
+		}
+	}
+	return out
+}
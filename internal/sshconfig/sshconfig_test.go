@@ -0,0 +1,196 @@
+package sshconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBlock(t *testing.T) {
+	got := mustBlock(t, "prod", []Host{
+		{Alias: "eg-bastion", HostName: "edge.example.com", Port: 41022, User: "ubuntu", HostKeyAlias: "eg-bastion", Comment: "session 1234"},
+		{Alias: "eg-db", HostName: "edge.example.com", Port: 41023},
+	})
+	want := `# BEGIN eg ssh-config: prod
+# Generated by eg ssh-config; edits inside this block are overwritten.
+
+# session 1234
+Host eg-bastion
+    HostName edge.example.com
+    Port 41022
+    User ubuntu
+    HostKeyAlias eg-bastion
+
+Host eg-db
+    HostName edge.example.com
+    Port 41023
+# END eg ssh-config: prod
+`
+	if got != want {
+		t.Errorf("Block() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBlockHostile(t *testing.T) {
+	// A resource name can't break out of its comment line.
+	got := mustBlock(t, "prod", []Host{{
+		Alias:    "eg-x",
+		HostName: "edge.example.com",
+		Port:     22,
+		Comment:  "x\r\nHost *\n    ProxyCommand touch /tmp/pwned\nMatch exec \"id\"",
+	}})
+	for _, line := range strings.Split(got, "\n") {
+		if strings.Contains(line, "ProxyCommand") && !strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "Match") || line == "Host *" {
+			t.Errorf("injected line %q in\n%s", line, got)
+		}
+	}
+
+	for _, h := range []Host{
+		{Alias: "eg-x", HostName: "edge.example.com\n    ProxyCommand sh", Port: 22},
+		{Alias: "eg-x", HostName: "edge.example.com", Port: 22, User: "root ProxyCommand=sh"},
+		{Alias: "eg-x", HostName: "edge.example.com", Port: 22, HostKeyAlias: "a\tb"},
+		{Alias: "eg x", HostName: "edge.example.com", Port: 22},
+		{Alias: "eg-x", Port: 22},
+	} {
+		if _, err := Block("prod", []Host{h}); err == nil {
+			t.Errorf("Block(%+v) succeeded, want an error", h)
+		}
+	}
+}
+
+func mustBlock(t *testing.T, profile string, hosts []Host) string {
+	t.Helper()
+	b, err := Block(profile, hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestUpdate(t *testing.T) {
+	block := mustBlock(t, "prod", []Host{{Alias: "eg-a", HostName: "h", Port: 1}})
+	other := mustBlock(t, "dev", nil)
+	user := "Host work\n    User me\n"
+
+	// Appended after existing entries, separated by a blank line.
+	got, err := Update(user+"\n\n", "prod", block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := user + "\n" + block; got != want {
+		t.Errorf("append =\n%s\nwant\n%s", got, want)
+	}
+
+	// Replaced in place; other profiles' blocks and user lines stay put.
+	config := user + "\n" + mustBlock(t, "prod", []Host{{Alias: "old", HostName: "h", Port: 9}}) + "\n" + other + "Host *\n"
+	got, err = Update(config, "prod", block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := user + "\n" + block + "\n" + other + "Host *\n"; got != want {
+		t.Errorf("replace =\n%s\nwant\n%s", got, want)
+	}
+
+	// Idempotent.
+	again, err := Update(got, "prod", block)
+	if err != nil || again != got {
+		t.Errorf("second Update changed the config: %v\n%s", err, again)
+	}
+
+	// Removed.
+	got, err = Update(got, "prod", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "eg-a") || !strings.Contains(got, "BEGIN eg ssh-config: dev") {
+		t.Errorf("remove =\n%s", got)
+	}
+	if got, _ := Update(user+"\n"+block, "prod", ""); got != user {
+		t.Errorf("remove last block =\n%q\nwant\n%q", got, user)
+	}
+
+	// Empty config, nothing to do.
+	if got, err := Update("", "prod", ""); err != nil || got != "" {
+		t.Errorf("Update(empty) = %q, %v", got, err)
+	}
+}
+
+func TestUpdateUnterminated(t *testing.T) {
+	_, err := Update("# BEGIN eg ssh-config: prod\nHost x\n", "prod", "")
+	if !errors.Is(err, ErrUnterminated) {
+		t.Errorf("err = %v, want ErrUnterminated", err)
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".ssh", "config")
+	block := mustBlock(t, "prod", []Host{{Alias: "eg-a", HostName: "h", Port: 1}})
+
+	changed, err := UpdateFile(path, "prod", block)
+	if err != nil || !changed {
+		t.Fatalf("UpdateFile() = %v, %v", changed, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != block {
+		t.Errorf("file =\n%s", data)
+	}
+	if fi, _ := os.Stat(path); runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", fi.Mode().Perm())
+	}
+
+	changed, err = UpdateFile(path, "prod", block)
+	if err != nil || changed {
+		t.Errorf("unchanged UpdateFile() = %v, %v", changed, err)
+	}
+
+	// A missing file with nothing to write is not created.
+	missing := filepath.Join(dir, "none")
+	if changed, err := UpdateFile(missing, "prod", ""); err != nil || changed {
+		t.Errorf("UpdateFile(missing) = %v, %v", changed, err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("missing file was created")
+	}
+}
+
+func TestAlias(t *testing.T) {
+	for name, want := range map[string]string{
+		"bastion":         "eg-bastion",
+		"Prod DB (eu-1)":  "eg-prod-db-eu-1",
+		"web_01.internal": "eg-web_01.internal",
+	} {
+		if got := Alias("eg-", name); got != want {
+			t.Errorf("Alias(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAliasesCollide(t *testing.T) {
+	got := Aliases("eg-", map[string]string{
+		"0a1b2c3d-0000-4000-8000-000000000001": "DB Prod",
+		"9f8e7d6c-0000-4000-8000-000000000002": "db-prod",
+		"5a5a5a5a-0000-4000-8000-000000000003": "db  prod ",
+		"11111111-0000-4000-8000-000000000004": "bastion",
+	})
+	want := map[string]string{
+		"0a1b2c3d-0000-4000-8000-000000000001": "eg-db-prod-0a1b2c3d",
+		"9f8e7d6c-0000-4000-8000-000000000002": "eg-db-prod-9f8e7d6c",
+		"5a5a5a5a-0000-4000-8000-000000000003": "eg-db-prod-5a5a5a5a",
+		"11111111-0000-4000-8000-000000000004": "eg-bastion",
+	}
+	for id, alias := range want {
+		if got[id] != alias {
+			t.Errorf("alias for %s = %q, want %q", id, got[id], alias)
+		}
+	}
+
+	hosts := []Host{{Alias: "eg-db", HostName: "h", Port: 1}, {Alias: "eg-db", HostName: "h", Port: 2}}
+	if _, err := Block("prod", hosts); err == nil {
+		t.Error("Block accepted a duplicate alias")
+	}
+}
@@ -0,0 +1,43 @@
+package entryguard
+
+import (
+	"context"
+	"net/url"
+)
+
+// Agent is an eg-agent instance registered in the organization. Optional
+// fields are empty until the agent has reported them.
+type Agent struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	AgentVersion    string `json:"agentVersion,omitempty"`
+	Hostname        string `json:"hostname,omitempty"`
+	OsInfo          string `json:"osInfo,omitempty"`
+	LastHeartbeatAt string `json:"lastHeartbeatAt,omitempty"`
+	CreatedAt       string `json:"createdAt"`
+}
+
+// ListAgents returns the eg-agent instances registered in the organization.
+// Requires org admin rights.
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	var agents []Agent
+	if err := c.do(ctx, "GET", "/agents", nil, &agents); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// GetAgent returns one registered agent by its ID. Requires org admin rights.
+func (c *Client) GetAgent(ctx context.Context, id string) (*Agent, error) {
+	var a Agent
+	if err := c.do(ctx, "GET", "/agents/"+url.PathEscape(id), nil, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeleteAgent deregisters an agent. Its API key stops working immediately.
+func (c *Client) DeleteAgent(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/agents/"+url.PathEscape(id), nil, nil)
+}
@@ -0,0 +1,71 @@
+package entryguard
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Well-known API key scopes.
+const (
+	ScopeAgentConnect = "agent:connect"
+)
+
+// ApiKey describes an API key. The secret itself is only returned once, when
+// the key is created; afterwards a key is identified by its KeyPrefix.
+type ApiKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	KeyPrefix  string   `json:"keyPrefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+// Matches reports whether secret is the full key this ApiKey describes.
+func (k *ApiKey) Matches(secret string) bool {
+	return k.KeyPrefix != "" && strings.HasPrefix(secret, k.KeyPrefix)
+}
+
+// CreateApiKeyRequest is the body of CreateApiKey. Without Scopes the server
+// grants its default scopes; an empty ExpiresAt never expires.
+type CreateApiKeyRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresAt string   `json:"expiresAt,omitempty"`
+}
+
+// CreatedApiKey is the response to CreateApiKey and carries the secret.
+type CreatedApiKey struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+// ListApiKeys returns the current user's API keys.
+func (c *Client) ListApiKeys(ctx context.Context) ([]ApiKey, error) {
+	var keys []ApiKey
+	if err := c.do(ctx, "GET", "/api-keys", nil, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// CreateApiKey creates an API key for the current user. The returned key
+// carries the secret, which the server does not show again.
+func (c *Client) CreateApiKey(ctx context.Context, req *CreateApiKeyRequest) (*CreatedApiKey, error) {
+	var key CreatedApiKey
+	if err := c.do(ctx, "POST", "/api-keys", req, &key); err != nil {
+		return nil, err
+	}
+	if key.Key == "" {
+		return nil, fmt.Errorf("server did not return the new key")
+	}
+	return &key, nil
+}
+
+// RevokeApiKey revokes a key. It stops working immediately.
+func (c *Client) RevokeApiKey(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api-keys/"+url.PathEscape(id), nil, nil)
+}
@@ -0,0 +1,233 @@
+package entryguard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the hosted EntryGuard API.
+const DefaultBaseURL = "https://app.entryguard.io/api/v1"
+
+// Client calls the EntryGuard API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	tokens     TokenSource
+	userAgent  string
+	retries    int
+	backoff    time.Duration
+}
+
+// TokenSource supplies OAuth access tokens, refreshing them as needed.
+type TokenSource interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the API base URL, e.g. https://eg.example.com/api/v1.
+func WithBaseURL(u string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimSuffix(u, "/") }
+}
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. to configure
+// TLS, proxies or timeouts.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithAPIKey authenticates with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithTokenSource authenticates with OAuth bearer tokens. It takes precedence
+// over WithAPIKey.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.tokens = ts }
+}
+
+// WithBearerToken authenticates with a fixed OAuth access token.
+func WithBearerToken(token string) Option {
+	return WithTokenSource(staticToken(token))
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithRetries retries idempotent requests (GET, PUT, DELETE) up to n times
+// on network errors and on 429, 502, 503 and 504 responses, with exponential
+// backoff starting at backoff (500ms if zero), capped at 5s, and honouring
+// Retry-After.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = n
+		if backoff > 0 {
+			c.backoff = backoff
+		}
+	}
+}
+
+type staticToken string
+
+func (t staticToken) AccessToken(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// NewClient returns a client for the hosted API with a 30 second timeout,
+// configured by opts.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "entryguard-go/" + Version,
+		backoff:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API base URL the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// do sends a request with a JSON body (if body is non-nil) and decodes the
+// JSON response into out (if out is non-nil).
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	retries := 0
+	if method == "GET" || method == "PUT" || method == "DELETE" {
+		retries = c.retries
+	}
+
+	for attempt := 0; ; attempt++ {
+		data, wait, err := c.send(ctx, method, path, payload)
+		if err == nil {
+			if out != nil && len(data) > 0 {
+				if err := json.Unmarshal(data, out); err != nil {
+					return fmt.Errorf("failed to parse response: %w", err)
+				}
+			}
+			return nil
+		}
+		if attempt >= retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if wait == 0 {
+			wait = min(c.backoff<<attempt, 5*time.Second)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send performs one attempt. wait is the server's Retry-After, if any.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (data []byte, wait time.Duration, err error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if c.tokens != nil {
+		token, err := c.tokens.AccessToken(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, &networkError{err}
+	}
+	defer resp.Body.Close()
+
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{}
+		if json.Unmarshal(data, apiErr) != nil || (apiErr.Message == "" && apiErr.Code == "") {
+			apiErr = &APIError{Body: string(data)}
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return nil, retryAfter(resp.Header.Get("Retry-After")), apiErr
+	}
+	return data, 0, nil
+}
+
+// networkError marks transport failures, which are worth retrying.
+type networkError struct{ err error }
+
+func (e *networkError) Error() string { return "request failed: " + e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+func retryable(err error) bool {
+	var ne *networkError
+	if errors.As(err, &ne) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, 30*time.Second)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(time.Until(t), 0), 30*time.Second)
+	}
+	return 0
+}
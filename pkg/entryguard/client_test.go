@@ -0,0 +1,231 @@
+package entryguard_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+func TestSessionLifecycle(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	srv.ClientIP = "203.0.113.7"
+	db := srv.AddResource(entryguard.Resource{Name: "db", ResourceType: "AWS_SG", Enabled: true})
+
+	ctx := context.Background()
+	client := srv.Client()
+
+	hours := 2
+	s, err := client.StartSession(ctx, &entryguard.StartSessionRequest{DurationHours: &hours, Reason: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != "ACTIVE" || s.Ipv4Address != "203.0.113.7" || len(s.ResourceIps) != 1 || s.ResourceIps[0].ResourceID != db.ID {
+		t.Fatalf("unexpected session: %+v", s)
+	}
+
+	s, err = client.ExtendSession(ctx, s.ID, &entryguard.ExtendSessionRequest{AdditionalMinutes: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := s.Remaining(time.Now()); d < 2*time.Hour+29*time.Minute {
+		t.Errorf("remaining after extend = %v", d)
+	}
+
+	if _, err := client.StopSession(ctx, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	active, err := client.ListSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Errorf("ListSessions after stop = %d sessions, want 0", len(active))
+	}
+
+	history, err := client.ListAllSessionHistory(ctx, entryguard.SessionHistoryQuery{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Status != "CANCELLED" || history[0].ResourceIps[0].Status != "REMOVED" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	now := time.Now()
+	srv.Now = func() time.Time { return now }
+
+	ctx := context.Background()
+	client := srv.Client()
+	minutes := 5
+	s, err := client.StartSession(ctx, &entryguard.StartSessionRequest{DurationMinutes: &minutes, Ipv4Address: "198.51.100.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(6 * time.Minute)
+	got, err := client.GetSession(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "EXPIRED" || got.EndedReason != entryguardtest.EndedExpired {
+		t.Errorf("status = %s (%s), want EXPIRED", got.Status, got.EndedReason)
+	}
+}
+
+func TestOrgSessionHistory(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	started := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	srv.AddSession(entryguard.Session{UserID: srv.User.ID, Status: "CANCELLED", StartedAt: started})
+	srv.AddSession(entryguard.Session{UserID: "other", UserEmail: "alice@example.com", Status: "CANCELLED", StartedAt: started})
+
+	ctx := context.Background()
+	client := srv.Client()
+	own, err := client.ListAllSessionHistory(ctx, entryguard.SessionHistoryQuery{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := client.ListAllOrgSessionHistory(ctx, entryguard.SessionHistoryQuery{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(own) != 1 || len(org) != 2 {
+		t.Errorf("own history = %d sessions, org history = %d; want 1 and 2", len(own), len(org))
+	}
+
+	srv.User.IsOrgAdmin = false
+	if _, err := client.ListAllOrgSessionHistory(ctx, entryguard.SessionHistoryQuery{}, 0); err == nil {
+		t.Error("org history succeeded for a non-admin")
+	}
+}
+
+func TestUpdateResourceClearsScript(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	res := srv.AddResource(entryguard.Resource{Name: "fw", ResourceType: "AGENT", ScriptDir: "/opt/eg", ScriptTimeout: 30, Enabled: true})
+
+	ctx := context.Background()
+	client := srv.Client()
+	req := res.Request()
+	dir := ""
+	req.ScriptDir = &dir
+	got, err := client.UpdateResource(ctx, res.ID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ScriptDir != "" || got.ScriptTimeout != 30 {
+		t.Errorf("updated resource = %+v, want script dir cleared and timeout kept", got)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := srv.Client().GetSession(ctx, "00000000-0000-4000-8000-00000000ffff")
+	if !errors.Is(err, entryguard.ErrNotFound) {
+		t.Errorf("missing session: err = %v, want ErrNotFound", err)
+	}
+	var apiErr *entryguard.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Error() != "Session not found" {
+		t.Errorf("missing session: err = %#v", err)
+	}
+
+	_, err = srv.Client(entryguard.WithAPIKey("wrong")).GetMe(ctx)
+	if !errors.Is(err, entryguard.ErrUnauthorized) {
+		t.Errorf("wrong key: err = %v, want ErrUnauthorized", err)
+	}
+
+	srv.User.IsOrgAdmin = false
+	_, err = srv.Client().ListAgents(ctx)
+	if !errors.Is(err, entryguard.ErrForbidden) {
+		t.Errorf("non-admin: err = %v, want ErrForbidden", err)
+	}
+}
+
+func TestAuthAndUserAgent(t *testing.T) {
+	var apiKey, authz, ua string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey, authz, ua = r.Header.Get("X-API-Key"), r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+		w.Write([]byte(`{"id":"u1"}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := entryguard.NewClient(entryguard.WithBaseURL(srv.URL+"/"), entryguard.WithAPIKey("k1"))
+	if _, err := client.GetMe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "k1" || authz != "" || ua != "entryguard-go/"+entryguard.Version {
+		t.Errorf("API key: X-API-Key = %q, Authorization = %q, User-Agent = %q", apiKey, authz, ua)
+	}
+
+	client = entryguard.NewClient(entryguard.WithBaseURL(srv.URL), entryguard.WithAPIKey("k1"),
+		entryguard.WithBearerToken("t1"), entryguard.WithUserAgent("app/1.0"))
+	if _, err := client.GetMe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "" || authz != "Bearer t1" || ua != "app/1.0" {
+		t.Errorf("bearer: X-API-Key = %q, Authorization = %q, User-Agent = %q", apiKey, authz, ua)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := entryguard.NewClient(entryguard.WithBaseURL(srv.URL), entryguard.WithRetries(2, time.Millisecond))
+	if _, err := client.ListSessions(ctx); err != nil {
+		t.Fatalf("with retries: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+
+	// POST is not idempotent and must not be retried.
+	calls.Store(0)
+	_, err := client.StartSession(ctx, &entryguard.StartSessionRequest{})
+	if err == nil || calls.Load() != 1 {
+		t.Errorf("POST: calls = %d, err = %v", calls.Load(), err)
+	}
+
+	calls.Store(0)
+	client = entryguard.NewClient(entryguard.WithBaseURL(srv.URL))
+	if _, err := client.ListSessions(ctx); err == nil {
+		t.Error("without retries: expected an error")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("without retries: calls = %d, want 1", n)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := srv.Client().GetMe(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
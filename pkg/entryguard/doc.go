@@ -0,0 +1,17 @@
+// Package entryguard is a Go client for the EntryGuard API.
+//
+//	client := entryguard.NewClient(entryguard.WithAPIKey(os.Getenv("EG_API_KEY")))
+//	session, err := client.StartSession(ctx, &entryguard.StartSessionRequest{
+//		Reason: "deploy",
+//	})
+//
+// Every method takes a context. Errors for HTTP error responses are
+// *APIError; test for common cases with errors.Is and ErrNotFound,
+// ErrUnauthorized, ErrForbidden or ErrRateLimited.
+//
+// Package entryguardtest provides an in-memory fake server for unit tests.
+package entryguard
+
+// Version is the version of this client library. It follows semantic
+// versioning and is sent in the default User-Agent.
+const Version = "0.1.0"
@@ -0,0 +1,131 @@
+package entryguardtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+func (b *Backend) listApiKeys(w http.ResponseWriter, r *http.Request) {
+	out := []entryguard.ApiKey{}
+	for _, k := range b.apiKeys {
+		out = append(out, k.ApiKey)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// createApiKey issues a random "eg_" key, which the backend accepts until it
+// is revoked. An agent registering with it becomes a separate agent.
+func (b *Backend) createApiKey(w http.ResponseWriter, r *http.Request) {
+	var req entryguard.CreateApiKeyRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	secret := "eg_" + hex.EncodeToString(raw)
+	key := &entryguard.CreatedApiKey{
+		ApiKey: entryguard.ApiKey{
+			ID:        b.newID(),
+			Name:      req.Name,
+			KeyPrefix: secret[:11],
+			Scopes:    nonNil(req.Scopes),
+			ExpiresAt: req.ExpiresAt,
+			CreatedAt: b.timestamp(),
+		},
+		Key: secret,
+	}
+	b.apiKeys = append(b.apiKeys, key)
+	b.record(r, "API_KEY_CREATED", "API_KEY", key.ID, key.Name)
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (b *Backend) revokeApiKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	i := slices.IndexFunc(b.apiKeys, func(k *entryguard.CreatedApiKey) bool { return k.ID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+	key := b.apiKeys[i]
+	b.apiKeys = slices.Delete(b.apiKeys, i, i+1)
+	b.record(r, "API_KEY_REVOKED", "API_KEY", key.ID, key.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) findAgent(w http.ResponseWriter, id string) *entryguard.Agent {
+	for _, a := range b.agents {
+		if a.ID == id {
+			return a
+		}
+	}
+	writeError(w, http.StatusNotFound, "Agent not found")
+	return nil
+}
+
+func (b *Backend) listAgents(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	out := []entryguard.Agent{}
+	for _, a := range b.agents {
+		out = append(out, *a)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (b *Backend) getAgent(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	if a := b.findAgent(w, r.PathValue("id")); a != nil {
+		writeJSON(w, http.StatusOK, a)
+	}
+}
+
+func (b *Backend) deleteAgent(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	a := b.findAgent(w, r.PathValue("id"))
+	if a == nil {
+		return
+	}
+	b.agents = slices.DeleteFunc(b.agents, func(x *entryguard.Agent) bool { return x == a })
+	b.record(r, "AGENT_DELETED", "AGENT", a.ID, a.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAudit filters the log like the real endpoint: since/until bound the
+// timestamp (inclusive), actor matches email or ID, resource matches name
+// or ID. Results are newest first.
+func (b *Backend) listAudit(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	q := r.URL.Query()
+	since, until := parseQueryTime(r, "since"), parseQueryTime(r, "until")
+	var out []entryguard.AuditEvent
+	for i := len(b.audit) - 1; i >= 0; i-- {
+		e := b.audit[i]
+		t := e.Time()
+		switch {
+		case !since.IsZero() && t.Before(since),
+			!until.IsZero() && t.After(until),
+			q.Get("actor") != "" && e.ActorID != q.Get("actor") && !strings.EqualFold(e.ActorEmail, q.Get("actor")),
+			q.Get("action") != "" && !strings.EqualFold(e.Action, q.Get("action")),
+			q.Get("resource") != "" && e.ResourceID != q.Get("resource") && e.ResourceName != q.Get("resource"):
+			continue
+		}
+		out = append(out, e)
+	}
+	writeJSON(w, http.StatusOK, pageOf(r, out))
+}
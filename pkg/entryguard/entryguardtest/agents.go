@@ -0,0 +1,340 @@
+package entryguardtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// Command states tracked by the backend.
+const (
+	CommandQueued    = "QUEUED"
+	CommandDelivered = "DELIVERED"
+	CommandSucceeded = "SUCCEEDED"
+	CommandFailed    = "FAILED"
+	CommandCancelled = "CANCELLED"
+)
+
+// AgentCommand is an APPLY or REVOKE command for an eg-agent. The JSON
+// fields are what the agent receives from /agents/commands/poll.
+type AgentCommand struct {
+	ID                 string `json:"id"`
+	CommandType        string `json:"commandType"`
+	CIDR               string `json:"cidr"`
+	Description        string `json:"description"`
+	ResourceIdentifier string `json:"resourceIdentifier"`
+	ResourceType       string `json:"resourceType"`
+	ScriptDir          string `json:"scriptDir,omitempty"`
+	ScriptTimeout      int    `json:"scriptTimeout,omitempty"`
+
+	// Bookkeeping, not sent to agents. AgentID is empty until an agent
+	// picks up a command for an unassigned resource.
+	AgentID    string `json:"-"`
+	SessionID  string `json:"-"`
+	ResourceID string `json:"-"`
+	Status     string `json:"-"`
+	Result     string `json:"-"`
+
+	ruleID string
+}
+
+type agentResult struct {
+	Success        bool   `json:"success"`
+	ResultMessage  string `json:"resultMessage"`
+	ProviderRuleID string `json:"providerRuleId"`
+}
+
+// agentManaged reports whether rules on res are applied by an eg-agent.
+// AGENT resources without an AgentID are served to whichever agent polls
+// first, so a single local agent needs no setup.
+func agentManaged(res *entryguard.Resource) bool {
+	return res.AgentID != "" || strings.EqualFold(res.ResourceType, "AGENT")
+}
+
+// ruleCIDR returns the rule's address as a CIDR, adding a host prefix when
+// it has none.
+func ruleCIDR(rule *entryguard.SessionResourceIp) string {
+	if strings.Contains(rule.IpAddress, "/") {
+		return rule.IpAddress
+	}
+	if rule.IpVersion == 6 {
+		return rule.IpAddress + "/128"
+	}
+	return rule.IpAddress + "/32"
+}
+
+// Commands returns a snapshot of every command queued so far, oldest first.
+func (b *Backend) Commands() []AgentCommand {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]AgentCommand, len(b.commands))
+	for i, c := range b.commands {
+		out[i] = *c
+	}
+	return out
+}
+
+func (b *Backend) enqueue(kind string, s *entryguard.Session, rule *entryguard.SessionResourceIp, res *entryguard.Resource) *AgentCommand {
+	cidr := ruleCIDR(rule)
+	desc := "EntryGuard session for " + s.UserEmail
+	if s.Reason != "" {
+		desc += ": " + s.Reason
+	}
+	c := &AgentCommand{
+		ID:                 b.newID(),
+		CommandType:        kind,
+		CIDR:               cidr,
+		Description:        desc,
+		ResourceIdentifier: res.ResourceIdentifier,
+		ResourceType:       res.ResourceType,
+		ScriptDir:          res.ScriptDir,
+		ScriptTimeout:      res.ScriptTimeout,
+		AgentID:            res.AgentID,
+		SessionID:          s.ID,
+		ResourceID:         res.ID,
+		Status:             CommandQueued,
+		ruleID:             rule.ID,
+	}
+	b.commands = append(b.commands, c)
+	return c
+}
+
+// revokeRule takes rule down when its session ends: immediately for
+// provider-managed resources and for APPLY commands no agent has picked up
+// yet, through a REVOKE command otherwise.
+func (b *Backend) revokeRule(s *entryguard.Session, rule *entryguard.SessionResourceIp, at string) {
+	if rule.Status != "APPLIED" && rule.Status != "PENDING" {
+		return
+	}
+	res := b.resource(rule.ResourceID)
+	if res == nil || !agentManaged(res) {
+		rule.Status = "REMOVED"
+		rule.RemovedAt = at
+		return
+	}
+	for _, c := range b.commands {
+		if c.ruleID == rule.ID && c.CommandType == "APPLY" && c.Status == CommandQueued {
+			c.Status = CommandCancelled
+			rule.Status = "REMOVED"
+			rule.RemovedAt = at
+			return
+		}
+	}
+	b.enqueue("REVOKE", s, rule, res)
+}
+
+func (b *Backend) resource(id string) *entryguard.Resource {
+	for _, res := range b.resources {
+		if res.ID == id {
+			return res
+		}
+	}
+	return nil
+}
+
+// agentKey returns the credential the request was made with; it identifies
+// the agent.
+func agentKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// callingAgent returns the agent registered with the request's key, writing
+// a 404 when there is none.
+func (b *Backend) callingAgent(w http.ResponseWriter, r *http.Request) *entryguard.Agent {
+	if id, ok := b.agentKeys[agentKey(r)]; ok {
+		for _, a := range b.agents {
+			if a.ID == id {
+				return a
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "Agent not registered. Run: eg-agent init")
+	return nil
+}
+
+func (b *Backend) registerAgent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name         string `json:"name"`
+		AgentVersion string `json:"agentVersion"`
+		Hostname     string `json:"hostname"`
+		OsInfo       string `json:"osInfo"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	// Registering again with the same key updates the existing agent.
+	key := agentKey(r)
+	var a *entryguard.Agent
+	if id, ok := b.agentKeys[key]; ok {
+		if i := slices.IndexFunc(b.agents, func(x *entryguard.Agent) bool { return x.ID == id }); i >= 0 {
+			a = b.agents[i]
+		}
+	}
+	if a == nil {
+		a = &entryguard.Agent{ID: b.newID(), CreatedAt: b.timestamp()}
+		b.agents = append(b.agents, a)
+		b.agentKeys[key] = a.ID
+	}
+	a.Name = req.Name
+	a.AgentVersion = req.AgentVersion
+	a.Hostname = req.Hostname
+	a.OsInfo = req.OsInfo
+	a.Status = "ONLINE"
+	a.LastHeartbeatAt = b.timestamp()
+	b.record(r, "AGENT_REGISTERED", "AGENT", a.ID, a.Name)
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (b *Backend) agentHeartbeat(w http.ResponseWriter, r *http.Request) {
+	a := b.callingAgent(w, r)
+	if a == nil {
+		return
+	}
+	var req struct {
+		AgentVersion string `json:"agentVersion"`
+		Hostname     string `json:"hostname"`
+		OsInfo       string `json:"osInfo"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.AgentVersion != "" {
+		a.AgentVersion = req.AgentVersion
+	}
+	if req.Hostname != "" {
+		a.Hostname = req.Hostname
+	}
+	if req.OsInfo != "" {
+		a.OsInfo = req.OsInfo
+	}
+	a.Status = "ONLINE"
+	a.LastHeartbeatAt = b.timestamp()
+	writeJSON(w, http.StatusOK, a)
+}
+
+// pollCommands hands the agent its queued commands, oldest first, and marks
+// them delivered. Commands are delivered once.
+func (b *Backend) pollCommands(w http.ResponseWriter, r *http.Request) {
+	a := b.callingAgent(w, r)
+	if a == nil {
+		return
+	}
+	b.expireSessions()
+	out := []AgentCommand{}
+	for _, c := range b.commands {
+		if c.Status != CommandQueued || (c.AgentID != "" && c.AgentID != a.ID) {
+			continue
+		}
+		c.AgentID = a.ID
+		c.Status = CommandDelivered
+		out = append(out, *c)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (b *Backend) commandResult(w http.ResponseWriter, r *http.Request) {
+	a := b.callingAgent(w, r)
+	if a == nil {
+		return
+	}
+	id := r.PathValue("id")
+	i := slices.IndexFunc(b.commands, func(c *AgentCommand) bool { return c.ID == id && c.AgentID == a.ID })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Command not found")
+		return
+	}
+	c := b.commands[i]
+	if c.Status != CommandDelivered {
+		writeError(w, http.StatusConflict, "Command already has a result")
+		return
+	}
+	var res agentResult
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		writeError(w, http.StatusBadRequest, "Malformed JSON request body")
+		return
+	}
+
+	c.Status, c.Result = CommandFailed, res.ResultMessage
+	if res.Success {
+		c.Status = CommandSucceeded
+	}
+	b.applyResult(c, &res)
+	w.WriteHeader(http.StatusOK)
+}
+
+// applyResult updates the session rule a command was for.
+func (b *Backend) applyResult(c *AgentCommand, res *agentResult) {
+	var s *entryguard.Session
+	var rule *entryguard.SessionResourceIp
+	for _, x := range b.sessions {
+		if x.ID != c.SessionID {
+			continue
+		}
+		for i := range x.ResourceIps {
+			if x.ResourceIps[i].ID == c.ruleID {
+				s, rule = x, &x.ResourceIps[i]
+			}
+		}
+	}
+	if rule == nil {
+		return
+	}
+
+	now := b.timestamp()
+	switch {
+	case c.CommandType == "APPLY" && res.Success:
+		rule.Status = "APPLIED"
+		rule.AppliedAt = now
+		rule.ProviderRuleId = res.ProviderRuleID
+		rule.ErrorMessage = ""
+	case c.CommandType == "APPLY":
+		rule.Status = "FAILED"
+		rule.ErrorMessage = res.ResultMessage
+	case res.Success:
+		rule.Status = "REMOVED"
+		rule.RemovedAt = now
+	default:
+		rule.Status = "FAILED"
+		rule.ErrorMessage = res.ResultMessage
+	}
+	if s.EndedAt == "" {
+		s.Status = sessionStatus(s)
+	}
+}
+
+// sessionStatus derives a live session's status from its rules: PENDING
+// while an agent has yet to apply one, then ACTIVE, PARTIAL or FAILED.
+func sessionStatus(s *entryguard.Session) string {
+	var pending, applied, failed int
+	for _, rule := range s.ResourceIps {
+		switch rule.Status {
+		case "PENDING":
+			pending++
+		case "APPLIED":
+			applied++
+		case "FAILED":
+			failed++
+		}
+	}
+	switch {
+	case pending > 0:
+		return "PENDING"
+	case failed == 0:
+		return "ACTIVE"
+	case applied > 0:
+		return "PARTIAL"
+	default:
+		return "FAILED"
+	}
+}
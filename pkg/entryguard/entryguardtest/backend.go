@@ -0,0 +1,344 @@
+package entryguardtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// APIKey is the key NewServer's backend accepts by default.
+const APIKey = "test-key"
+
+// Backend is an in-memory implementation of the EntryGuard API, including
+// the endpoints eg-agent uses. Sessions expire lazily, when they are next
+// read. Requests may be served with or without the /api/v1 prefix.
+//
+// Rules on agent-managed resources (those with an AgentID, or of type AGENT)
+// start out PENDING and are turned into APPLY commands for the agent; when
+// the session is stopped or expires, REVOKE commands follow. The session
+// becomes ACTIVE once the agent reports success.
+//
+// Rules on TUNNEL resources apply instantly and carry the provider rule ID
+// "tunnel:<port>:<cidr>", with one port per resource from TunnelPortBase up,
+// and TunnelHost.
+//
+// The zero value is not usable; create one with NewBackend.
+type Backend struct {
+	// APIKey is accepted in X-API-Key, as are keys created through
+	// /api-keys; bearer tokens are accepted when they equal it too. Empty
+	// accepts any request.
+	APIKey string
+
+	// User is returned from /auth/me and recorded as the owner of new
+	// sessions and as the actor of audit events.
+	User entryguard.UserInfo
+
+	// ClientIP, when set, is reported by /detect-ip and used for sessions
+	// started without an address, instead of the request's remote address.
+	ClientIP string
+
+	// TunnelHost is reported as the edge host of tunnel rules. Empty
+	// leaves it out, like servers that predate the field.
+	TunnelHost string
+
+	// Now is the backend clock; tests may replace it to expire sessions.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	nextID    int
+	sessions  []*entryguard.Session
+	resources []*entryguard.Resource
+	apiKeys   []*entryguard.CreatedApiKey
+	agents    []*entryguard.Agent
+	audit     []entryguard.AuditEvent
+	commands  []*AgentCommand
+	agentKeys map[string]string // credential -> agent ID
+	tunnels   map[string]int    // resource ID -> edge port
+
+	mux *http.ServeMux
+}
+
+// NewBackend returns an empty backend that accepts APIKey and whose user is
+// an org admin.
+func NewBackend() *Backend {
+	b := &Backend{
+		APIKey: APIKey,
+		User: entryguard.UserInfo{
+			ID:               "00000000-0000-4000-8000-000000000001",
+			Email:            "dev@example.com",
+			Name:             "Dev User",
+			IsOrgAdmin:       true,
+			OrganizationID:   "00000000-0000-4000-8000-000000000002",
+			OrganizationName: "Example",
+			OrganizationSlug: "example",
+			SubscriptionTier: "FREE",
+		},
+		Now:       time.Now,
+		nextID:    16,
+		agentKeys: make(map[string]string),
+		tunnels:   make(map[string]int),
+		mux:       http.NewServeMux(),
+	}
+	b.routes()
+	return b
+}
+
+func (b *Backend) routes() {
+	b.handle("GET /auth/me", b.getMe)
+	b.handle("GET /detect-ip", b.detectIP)
+
+	b.handle("GET /sessions", b.listSessions)
+	b.handle("POST /sessions", b.startSession)
+	b.handle("GET /sessions/history", b.sessionHistory)
+	b.handle("GET /sessions/{id}", b.getSession)
+	b.handle("POST /sessions/{id}/stop", b.stopSession)
+	b.handle("POST /sessions/{id}/extend", b.extendSession)
+	b.handle("GET /admin/sessions", b.listOrgSessions)
+	b.handle("GET /admin/sessions/history", b.orgSessionHistory)
+	b.handle("POST /admin/sessions/{id}/stop", b.stopOrgSession)
+
+	b.handle("GET /resources", b.listResources)
+	b.handle("POST /resources", b.createResource)
+	b.handle("GET /resources/{id}", b.getResource)
+	b.handle("PUT /resources/{id}", b.updateResource)
+	b.handle("DELETE /resources/{id}", b.deleteResource)
+
+	b.handle("GET /api-keys", b.listApiKeys)
+	b.handle("POST /api-keys", b.createApiKey)
+	b.handle("DELETE /api-keys/{id}", b.revokeApiKey)
+
+	b.handle("POST /agents/register", b.registerAgent)
+	b.handle("POST /agents/heartbeat", b.agentHeartbeat)
+	b.handle("GET /agents/commands/poll", b.pollCommands)
+	b.handle("POST /agents/commands/{id}/result", b.commandResult)
+	b.handle("GET /agents", b.listAgents)
+	b.handle("GET /agents/{id}", b.getAgent)
+	b.handle("DELETE /agents/{id}", b.deleteAgent)
+
+	b.handle("GET /audit-logs", b.listAudit)
+}
+
+// handle registers h under pattern, holding the backend lock while it runs.
+func (b *Backend) handle(pattern string, h func(w http.ResponseWriter, r *http.Request)) {
+	b.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		h(w, r)
+	})
+}
+
+func (b *Backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p := strings.TrimPrefix(r.URL.Path, "/api/v1"); p != r.URL.Path {
+		r2 := *r
+		u := *r.URL
+		u.Path = p
+		r2.URL = &u
+		r = &r2
+	}
+	b.mu.Lock()
+	ok := b.authorized(r)
+	b.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+	b.mux.ServeHTTP(w, r)
+}
+
+func (b *Backend) authorized(r *http.Request) bool {
+	if b.APIKey == "" {
+		return true
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		if key == b.APIKey {
+			return true
+		}
+		for _, k := range b.apiKeys {
+			if k.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+	return r.Header.Get("Authorization") == "Bearer "+b.APIKey
+}
+
+// AddResource stores r, assigning an ID if it has none, and returns the
+// stored copy.
+func (b *Backend) AddResource(r entryguard.Resource) entryguard.Resource {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.ID == "" {
+		r.ID = b.newID()
+	}
+	if r.CreatedAt == "" {
+		r.CreatedAt = b.timestamp()
+	}
+	b.resources = append(b.resources, &r)
+	return r
+}
+
+// AddAgent stores a, assigning an ID if it has none, and returns the stored
+// copy.
+func (b *Backend) AddAgent(a entryguard.Agent) entryguard.Agent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if a.ID == "" {
+		a.ID = b.newID()
+	}
+	if a.Status == "" {
+		a.Status = "OFFLINE"
+	}
+	if a.CreatedAt == "" {
+		a.CreatedAt = b.timestamp()
+	}
+	b.agents = append(b.agents, &a)
+	return a
+}
+
+// AddSession stores s as-is (assigning an ID if it has none), e.g. to seed
+// another user's session or past history.
+func (b *Backend) AddSession(s entryguard.Session) entryguard.Session {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.ID == "" {
+		s.ID = b.newID()
+	}
+	b.sessions = append(b.sessions, &s)
+	return s
+}
+
+// Sessions returns a snapshot of all sessions, oldest first.
+func (b *Backend) Sessions() []entryguard.Session {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expireSessions()
+	out := make([]entryguard.Session, len(b.sessions))
+	for i, s := range b.sessions {
+		out[i] = *s
+	}
+	return out
+}
+
+// AuditEvents returns a snapshot of the audit log, oldest first.
+func (b *Backend) AuditEvents() []entryguard.AuditEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.audit)
+}
+
+// newID returns a fresh UUID-shaped ID.
+func (b *Backend) newID() string {
+	b.nextID++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", b.nextID, b.nextID)
+}
+
+func (b *Backend) timestamp() string {
+	return b.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func (b *Backend) record(r *http.Request, action, resourceType, resourceID, resourceName string) {
+	b.audit = append(b.audit, entryguard.AuditEvent{
+		ID:           b.newID(),
+		Timestamp:    b.timestamp(),
+		ActorID:      b.User.ID,
+		ActorEmail:   b.User.Email,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		ResourceName: resourceName,
+		IpAddress:    b.clientIP(r),
+	})
+}
+
+func (b *Backend) clientIP(r *http.Request) string {
+	if b.ClientIP != "" {
+		return b.ClientIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requireAdmin writes a 403 and returns false unless the user is an org
+// admin.
+func (b *Backend) requireAdmin(w http.ResponseWriter) bool {
+	if !b.User.IsOrgAdmin {
+		writeError(w, http.StatusForbidden, "Organization admin rights required")
+		return false
+	}
+	return true
+}
+
+func (b *Backend) getMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.User)
+}
+
+func (b *Backend) detectIP(w http.ResponseWriter, r *http.Request) {
+	ip := b.clientIP(r)
+	version := 4
+	if strings.Contains(ip, ":") {
+		version = 6
+	}
+	writeJSON(w, http.StatusOK, entryguard.IpResponse{IP: ip, Version: version, Timestamp: b.timestamp()})
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Malformed JSON request body")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{
+		"status":  status,
+		"error":   http.StatusText(status),
+		"message": msg,
+	})
+}
+
+// pageOf slices items according to the page and size query parameters, in
+// the Spring-style envelope the API uses.
+func pageOf[T any](r *http.Request, items []T) map[string]any {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	if size <= 0 {
+		size = 20
+	}
+	page = max(page, 0)
+	total := len(items)
+	from := min(page*size, total)
+	to := min(from+size, total)
+	pages := (total + size - 1) / size
+	return map[string]any{
+		"content":       append([]T{}, items[from:to]...),
+		"number":        page,
+		"size":          size,
+		"totalElements": total,
+		"totalPages":    pages,
+		"last":          page >= pages-1,
+	}
+}
+
+func parseQueryTime(r *http.Request, key string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, r.URL.Query().Get(key))
+	return t
+}
@@ -0,0 +1,98 @@
+package entryguardtest
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+func (b *Backend) findResource(w http.ResponseWriter, id string) *entryguard.Resource {
+	for _, res := range b.resources {
+		if res.ID == id {
+			return res
+		}
+	}
+	writeError(w, http.StatusNotFound, "Resource not found")
+	return nil
+}
+
+func validResource(w http.ResponseWriter, req *entryguard.ResourceRequest) bool {
+	if req.Name == "" || req.ResourceType == "" {
+		writeError(w, http.StatusBadRequest, "name and resourceType are required")
+		return false
+	}
+	return true
+}
+
+func (b *Backend) listResources(w http.ResponseWriter, r *http.Request) {
+	out := []entryguard.Resource{}
+	for _, res := range b.resources {
+		out = append(out, *res)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (b *Backend) getResource(w http.ResponseWriter, r *http.Request) {
+	if res := b.findResource(w, r.PathValue("id")); res != nil {
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (b *Backend) createResource(w http.ResponseWriter, r *http.Request) {
+	var req entryguard.ResourceRequest
+	if !b.requireAdmin(w) || !readJSON(w, r, &req) || !validResource(w, &req) {
+		return
+	}
+	res := &entryguard.Resource{ID: b.newID(), CreatedAt: b.timestamp()}
+	applyResource(res, &req)
+	b.resources = append(b.resources, res)
+	b.record(r, "RESOURCE_CREATED", "RESOURCE", res.ID, res.Name)
+	writeJSON(w, http.StatusCreated, res)
+}
+
+func (b *Backend) updateResource(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	res := b.findResource(w, r.PathValue("id"))
+	if res == nil {
+		return
+	}
+	var req entryguard.ResourceRequest
+	if !readJSON(w, r, &req) || !validResource(w, &req) {
+		return
+	}
+	applyResource(res, &req)
+	res.UpdatedAt = b.timestamp()
+	b.record(r, "RESOURCE_UPDATED", "RESOURCE", res.ID, res.Name)
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (b *Backend) deleteResource(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	res := b.findResource(w, r.PathValue("id"))
+	if res == nil {
+		return
+	}
+	b.resources = slices.DeleteFunc(b.resources, func(x *entryguard.Resource) bool { return x == res })
+	b.record(r, "RESOURCE_DELETED", "RESOURCE", res.ID, res.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func applyResource(res *entryguard.Resource, req *entryguard.ResourceRequest) {
+	res.Name = req.Name
+	res.ResourceType = req.ResourceType
+	res.ResourceIdentifier = req.ResourceIdentifier
+	res.Description = req.Description
+	res.AgentID = req.AgentID
+	if req.ScriptDir != nil {
+		res.ScriptDir = *req.ScriptDir
+	}
+	if req.ScriptTimeout != nil {
+		res.ScriptTimeout = *req.ScriptTimeout
+	}
+	res.Enabled = req.Enabled
+}
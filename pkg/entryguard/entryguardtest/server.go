@@ -0,0 +1,36 @@
+// Package entryguardtest provides an in-memory EntryGuard API for tests.
+//
+//	srv := entryguardtest.NewServer()
+//	defer srv.Close()
+//	srv.AddResource(entryguard.Resource{Name: "db", ResourceType: "AWS_SG", Enabled: true})
+//	client := srv.Client()
+package entryguardtest
+
+import (
+	"net/http/httptest"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// Server is an httptest server running a Backend.
+type Server struct {
+	*httptest.Server
+	*Backend
+}
+
+// NewServer starts a server with a fresh backend. The caller must Close it.
+func NewServer() *Server {
+	b := NewBackend()
+	return &Server{Server: httptest.NewServer(b), Backend: b}
+}
+
+// Client returns a client for the server authenticated with the backend's
+// API key. opts are applied after the defaults and may override them.
+func (s *Server) Client(opts ...entryguard.Option) *entryguard.Client {
+	defaults := []entryguard.Option{
+		entryguard.WithBaseURL(s.URL),
+		entryguard.WithHTTPClient(s.Server.Client()),
+		entryguard.WithAPIKey(s.APIKey),
+	}
+	return entryguard.NewClient(append(defaults, opts...)...)
+}
@@ -0,0 +1,297 @@
+package entryguardtest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// Ended reasons recorded on sessions.
+const (
+	EndedExpired = "EXPIRED"
+	EndedStopped = "USER_STOPPED"
+	EndedAdmin   = "ADMIN_STOPPED"
+)
+
+// expireSessions ends active sessions whose expiry has passed.
+func (b *Backend) expireSessions() {
+	now := b.Now()
+	for _, s := range b.sessions {
+		if d, ok := s.Remaining(now); ok && d <= 0 && live(s) {
+			b.endSession(s, "EXPIRED", EndedExpired, s.ExpiresAt)
+		}
+	}
+}
+
+func (b *Backend) endSession(s *entryguard.Session, status, reason, at string) {
+	s.Status = status
+	s.EndedReason = reason
+	s.EndedAt = at
+	for i := range s.ResourceIps {
+		b.revokeRule(s, &s.ResourceIps[i], at)
+	}
+}
+
+// live reports whether s has not ended yet.
+func live(s *entryguard.Session) bool {
+	return s.IsActive() || s.Status == "PENDING"
+}
+
+// ownSession returns the current user's session with the given ID, writing
+// a 404 when there is none.
+func (b *Backend) ownSession(w http.ResponseWriter, id string) *entryguard.Session {
+	b.expireSessions()
+	for _, s := range b.sessions {
+		if s.ID == id && s.UserID == b.User.ID {
+			return s
+		}
+	}
+	writeError(w, http.StatusNotFound, "Session not found")
+	return nil
+}
+
+// ownSessions returns the current user's sessions, newest first. With
+// activeOnly, ended sessions are left out.
+func (b *Backend) ownSessions(activeOnly bool) []entryguard.Session {
+	b.expireSessions()
+	var out []entryguard.Session
+	for i := len(b.sessions) - 1; i >= 0; i-- {
+		s := b.sessions[i]
+		if s.UserID != b.User.ID || (activeOnly && !live(s)) {
+			continue
+		}
+		out = append(out, *s)
+	}
+	return out
+}
+
+func (b *Backend) listSessions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, nonNil(b.ownSessions(true)))
+}
+
+func (b *Backend) getSession(w http.ResponseWriter, r *http.Request) {
+	if s := b.ownSession(w, r.PathValue("id")); s != nil {
+		writeJSON(w, http.StatusOK, s)
+	}
+}
+
+func (b *Backend) startSession(w http.ResponseWriter, r *http.Request) {
+	var req entryguard.StartSessionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	length := time.Hour
+	switch {
+	case req.DurationMinutes != nil:
+		length = time.Duration(*req.DurationMinutes) * time.Minute
+	case req.DurationHours != nil:
+		length = time.Duration(*req.DurationHours) * time.Hour
+	}
+	if length <= 0 {
+		writeError(w, http.StatusBadRequest, "Session duration must be positive")
+		return
+	}
+
+	ipv4, ipv6 := req.Ipv4Address, req.Ipv6Address
+	if ipv4 == "" && ipv6 == "" {
+		if ip := b.clientIP(r); strings.Contains(ip, ":") {
+			ipv6 = ip
+		} else {
+			ipv4 = ip
+		}
+	}
+
+	var targets []*entryguard.Resource
+	for _, res := range b.resources {
+		if !res.Enabled {
+			continue
+		}
+		if len(req.ResourceIDs) > 0 && !slices.Contains(req.ResourceIDs, res.ID) {
+			continue
+		}
+		targets = append(targets, res)
+	}
+	for _, id := range req.ResourceIDs {
+		if !slices.ContainsFunc(targets, func(res *entryguard.Resource) bool { return res.ID == id }) {
+			writeError(w, http.StatusBadRequest, "Unknown or disabled resource: "+id)
+			return
+		}
+	}
+
+	now := b.Now()
+	s := &entryguard.Session{
+		ID:               b.newID(),
+		UserID:           b.User.ID,
+		UserName:         b.User.Name,
+		UserEmail:        b.User.Email,
+		Ipv4Address:      ipv4,
+		Ipv6Address:      ipv6,
+		Status:           "ACTIVE",
+		StartedAt:        now.UTC().Format(time.RFC3339Nano),
+		ExpiresAt:        now.Add(length).UTC().Format(time.RFC3339Nano),
+		Reason:           req.Reason,
+		TicketRef:        req.TicketRef,
+		CreatedAt:        now.UTC().Format(time.RFC3339Nano),
+		OrganizationName: b.User.OrganizationName,
+	}
+	for _, res := range targets {
+		for _, ip := range []struct {
+			addr    string
+			version int
+			prefix  int
+		}{{ipv4, 4, req.Ipv4PrefixLen}, {ipv6, 6, req.Ipv6PrefixLen}} {
+			if ip.addr == "" {
+				continue
+			}
+			addr := ip.addr
+			if ip.prefix > 0 {
+				addr += "/" + strconv.Itoa(ip.prefix)
+			}
+			rule := entryguard.SessionResourceIp{
+				ID:           b.newID(),
+				ResourceID:   res.ID,
+				ResourceName: res.Name,
+				IpVersion:    ip.version,
+				IpAddress:    addr,
+				Status:       "APPLIED",
+				AppliedAt:    s.StartedAt,
+			}
+			switch {
+			case agentManaged(res):
+				rule.Status, rule.AppliedAt = "PENDING", ""
+				b.enqueue("APPLY", s, &rule, res)
+			case isTunnel(res):
+				b.tunnelRule(&rule, res)
+			}
+			s.ResourceIps = append(s.ResourceIps, rule)
+		}
+	}
+	s.Status = sessionStatus(s)
+
+	b.sessions = append(b.sessions, s)
+	b.record(r, "SESSION_STARTED", "SESSION", s.ID, "")
+	writeJSON(w, http.StatusCreated, s)
+}
+
+func (b *Backend) stopSession(w http.ResponseWriter, r *http.Request) {
+	s := b.ownSession(w, r.PathValue("id"))
+	if s == nil {
+		return
+	}
+	if !live(s) {
+		writeError(w, http.StatusConflict, "Session is not active")
+		return
+	}
+	b.endSession(s, "CANCELLED", EndedStopped, b.timestamp())
+	b.record(r, "SESSION_STOPPED", "SESSION", s.ID, "")
+	writeJSON(w, http.StatusOK, s)
+}
+
+func (b *Backend) extendSession(w http.ResponseWriter, r *http.Request) {
+	s := b.ownSession(w, r.PathValue("id"))
+	if s == nil {
+		return
+	}
+	var req entryguard.ExtendSessionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if !s.IsActive() {
+		writeError(w, http.StatusConflict, "Session is not active")
+		return
+	}
+	extra := time.Duration(req.AdditionalHours)*time.Hour + time.Duration(req.AdditionalMinutes)*time.Minute
+	if extra <= 0 {
+		writeError(w, http.StatusBadRequest, "Extension must be positive")
+		return
+	}
+	expires, _ := time.Parse(time.RFC3339Nano, s.ExpiresAt)
+	s.ExpiresAt = expires.Add(extra).UTC().Format(time.RFC3339Nano)
+	b.record(r, "SESSION_EXTENDED", "SESSION", s.ID, "")
+	writeJSON(w, http.StatusOK, s)
+}
+
+func (b *Backend) sessionHistory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, pageOf(r, startedBetween(r, b.ownSessions(false))))
+}
+
+func (b *Backend) orgSessionHistory(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	b.expireSessions()
+	var all []entryguard.Session
+	for i := len(b.sessions) - 1; i >= 0; i-- {
+		all = append(all, *b.sessions[i])
+	}
+	writeJSON(w, http.StatusOK, pageOf(r, startedBetween(r, all)))
+}
+
+// startedBetween keeps the sessions started within the request's since and
+// until parameters.
+func startedBetween(r *http.Request, sessions []entryguard.Session) []entryguard.Session {
+	since, until := parseQueryTime(r, "since"), parseQueryTime(r, "until")
+	var out []entryguard.Session
+	for _, s := range sessions {
+		started, _ := time.Parse(time.RFC3339Nano, s.StartedAt)
+		if (!since.IsZero() && started.Before(since)) || (!until.IsZero() && started.After(until)) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func (b *Backend) listOrgSessions(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	b.expireSessions()
+	user := r.URL.Query().Get("user")
+	out := []entryguard.Session{}
+	for i := len(b.sessions) - 1; i >= 0; i-- {
+		s := b.sessions[i]
+		if !live(s) {
+			continue
+		}
+		if user != "" && s.UserID != user && !strings.EqualFold(s.UserEmail, user) {
+			continue
+		}
+		out = append(out, *s)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (b *Backend) stopOrgSession(w http.ResponseWriter, r *http.Request) {
+	if !b.requireAdmin(w) {
+		return
+	}
+	b.expireSessions()
+	id := r.PathValue("id")
+	for _, s := range b.sessions {
+		if s.ID != id {
+			continue
+		}
+		if !live(s) {
+			writeError(w, http.StatusConflict, "Session is not active")
+			return
+		}
+		b.endSession(s, "CANCELLED", EndedAdmin, b.timestamp())
+		b.record(r, "SESSION_STOPPED", "SESSION", s.ID, "")
+		writeJSON(w, http.StatusOK, s)
+		return
+	}
+	writeError(w, http.StatusNotFound, "Session not found")
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package entryguardtest
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+)
+
+// TunnelPortBase is the edge port given to the first TUNNEL resource.
+const TunnelPortBase = 41000
+
+func isTunnel(res *entryguard.Resource) bool {
+	return strings.EqualFold(res.ResourceType, "TUNNEL")
+}
+
+// tunnelRule turns rule into a tunnel rule on res. Callers hold b.mu.
+func (b *Backend) tunnelRule(rule *entryguard.SessionResourceIp, res *entryguard.Resource) {
+	cidr, err := netip.ParsePrefix(ruleCIDR(rule))
+	if err != nil {
+		return
+	}
+	rule.ProviderRuleId = entryguard.Tunnel{Port: b.tunnelPort(res), CIDR: cidr}.String()
+	rule.TunnelHost = b.TunnelHost
+}
+
+// tunnelPort returns the edge port of res, assigning the next free one the
+// first time a session uses it. Callers hold b.mu.
+func (b *Backend) tunnelPort(res *entryguard.Resource) int {
+	port, ok := b.tunnels[res.ID]
+	if !ok {
+		port = TunnelPortBase + len(b.tunnels)
+		b.tunnels[res.ID] = port
+	}
+	return port
+}
@@ -0,0 +1,55 @@
+package entryguard
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by *APIError through errors.Is.
+var (
+	ErrUnauthorized = errors.New("entryguard: unauthorized")
+	ErrForbidden    = errors.New("entryguard: forbidden")
+	ErrNotFound     = errors.New("entryguard: not found")
+	ErrConflict     = errors.New("entryguard: conflict")
+	ErrRateLimited  = errors.New("entryguard: rate limited")
+)
+
+// APIError is returned when the API answers with an HTTP error status.
+type APIError struct {
+	StatusCode       int               `json:"status"`
+	Message          string            `json:"message"`
+	Code             string            `json:"error"`
+	ValidationErrors map[string]string `json:"validationErrors,omitempty"`
+
+	// Body is the raw response body, kept when it is not a JSON error.
+	Body string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Code != "":
+		return e.Code
+	default:
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+	}
+}
+
+// Is maps the status code onto the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package entryguard_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+func Example() {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	srv.AddResource(entryguard.Resource{Name: "db", ResourceType: "AWS_SG", Enabled: true})
+
+	client := entryguard.NewClient(
+		entryguard.WithBaseURL(srv.URL),
+		entryguard.WithAPIKey(entryguardtest.APIKey),
+		entryguard.WithRetries(3, 0),
+	)
+
+	minutes := 30
+	s, err := client.StartSession(context.Background(), &entryguard.StartSessionRequest{
+		DurationMinutes: &minutes,
+		Ipv4Address:     "203.0.113.7",
+		Reason:          "deploy",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(s.Status, s.ResourceIps[0].ResourceName, s.ResourceIps[0].IpAddress)
+	// Output: ACTIVE db 203.0.113.7
+}
@@ -0,0 +1,92 @@
+package entryguard
+
+import (
+	"context"
+	"net/url"
+)
+
+// Resource is something sessions whitelist on: a cloud security group, a
+// firewall managed by an eg-agent, a tunnel target, etc. The script fields
+// only apply to agent-managed resources and are passed to the agent with
+// every APPLY/REVOKE command.
+type Resource struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	ResourceType       string `json:"resourceType"`
+	ResourceIdentifier string `json:"resourceIdentifier,omitempty"`
+	Description        string `json:"description"`
+	AgentID            string `json:"agentId,omitempty"`
+	ScriptDir          string `json:"scriptDir,omitempty"`
+	ScriptTimeout      int    `json:"scriptTimeout,omitempty"`
+	Enabled            bool   `json:"enabled"`
+	CreatedAt          string `json:"createdAt,omitempty"`
+	UpdatedAt          string `json:"updatedAt,omitempty"`
+}
+
+// ResourceRequest is the body for creating or replacing a resource.
+// ScriptTimeout is in seconds. The script fields are pointers so an update
+// can clear them: nil leaves a field out of the request, while a pointer to
+// "" or 0 sends it.
+type ResourceRequest struct {
+	Name               string  `json:"name"`
+	ResourceType       string  `json:"resourceType"`
+	ResourceIdentifier string  `json:"resourceIdentifier,omitempty"`
+	Description        string  `json:"description,omitempty"`
+	AgentID            string  `json:"agentId,omitempty"`
+	ScriptDir          *string `json:"scriptDir,omitempty"`
+	ScriptTimeout      *int    `json:"scriptTimeout,omitempty"`
+	Enabled            bool    `json:"enabled"`
+}
+
+// Request returns a ResourceRequest carrying the resource's current settings,
+// as a starting point for an update.
+func (r *Resource) Request() *ResourceRequest {
+	dir, timeout := r.ScriptDir, r.ScriptTimeout
+	return &ResourceRequest{
+		Name:               r.Name,
+		ResourceType:       r.ResourceType,
+		ResourceIdentifier: r.ResourceIdentifier,
+		Description:        r.Description,
+		AgentID:            r.AgentID,
+		ScriptDir:          &dir,
+		ScriptTimeout:      &timeout,
+		Enabled:            r.Enabled,
+	}
+}
+
+// ListResources returns the resources the current user can whitelist on.
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	if err := c.do(ctx, "GET", "/resources", nil, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetResource returns one resource by its ID.
+func (c *Client) GetResource(ctx context.Context, id string) (*Resource, error) {
+	return c.resource(ctx, "GET", "/resources/"+url.PathEscape(id), nil)
+}
+
+// CreateResource creates a resource. Requires org admin rights.
+func (c *Client) CreateResource(ctx context.Context, req *ResourceRequest) (*Resource, error) {
+	return c.resource(ctx, "POST", "/resources", req)
+}
+
+// UpdateResource replaces a resource's settings. Requires org admin rights.
+func (c *Client) UpdateResource(ctx context.Context, id string, req *ResourceRequest) (*Resource, error) {
+	return c.resource(ctx, "PUT", "/resources/"+url.PathEscape(id), req)
+}
+
+// DeleteResource deletes a resource. Requires org admin rights.
+func (c *Client) DeleteResource(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/resources/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) resource(ctx context.Context, method, path string, body any) (*Resource, error) {
+	var r Resource
+	if err := c.do(ctx, method, path, body, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,279 @@
+package entryguard
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Session is a time-limited whitelisting of the caller's IP addresses on a
+// set of resources.
+type Session struct {
+	ID               string              `json:"id"`
+	UserID           string              `json:"userId"`
+	UserName         string              `json:"userName"`
+	UserEmail        string              `json:"userEmail"`
+	Ipv4Address      string              `json:"ipv4Address"`
+	Ipv6Address      string              `json:"ipv6Address"`
+	Status           string              `json:"status"`
+	StartedAt        string              `json:"startedAt"`
+	ExpiresAt        string              `json:"expiresAt"`
+	EndedAt          string              `json:"endedAt"`
+	EndedReason      string              `json:"endedReason"`
+	Reason           string              `json:"reason"`
+	TicketRef        string              `json:"ticketRef"`
+	ResourceIps      []SessionResourceIp `json:"resourceIps"`
+	CreatedAt        string              `json:"createdAt"`
+	OrganizationName string              `json:"organizationName"`
+}
+
+// SessionResourceIp is the state of one whitelist rule of a session.
+type SessionResourceIp struct {
+	ID             string `json:"id"`
+	ResourceID     string `json:"resourceId"`
+	ResourceName   string `json:"resourceName"`
+	IpVersion      int    `json:"ipVersion"`
+	IpAddress      string `json:"ipAddress"`
+	Status         string `json:"status"`
+	ProviderRuleId string `json:"providerRuleId"`
+	AppliedAt      string `json:"appliedAt"`
+	RemovedAt      string `json:"removedAt"`
+	ErrorMessage   string `json:"errorMessage"`
+	// TunnelHost is the edge host serving a tunnel rule. Servers that
+	// predate it leave it empty; see DefaultTunnelHost.
+	TunnelHost string `json:"tunnelHost,omitempty"`
+}
+
+// IsActive reports whether the session currently has whitelist rules applied.
+// PARTIAL sessions count: some resources failed, but the rest are in place.
+func (s *Session) IsActive() bool {
+	return s.Status == "ACTIVE" || s.Status == "PARTIAL"
+}
+
+// Remaining returns the time left until the session expires. ok is false if
+// the session has no parseable expiry.
+func (s *Session) Remaining(now time.Time) (d time.Duration, ok bool) {
+	if s.ExpiresAt == "" {
+		return 0, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s.ExpiresAt)
+	if err != nil {
+		return 0, false
+	}
+	return t.Sub(now), true
+}
+
+// Covers reports whether the session whitelists ip, either as its exact
+// address or through a CIDR applied to one of its resources.
+func (s *Session) Covers(ip netip.Addr) bool {
+	for _, candidate := range []string{s.Ipv4Address, s.Ipv6Address} {
+		if prefixContains(candidate, ip) {
+			return true
+		}
+	}
+	for _, r := range s.ResourceIps {
+		if r.Status == "REMOVED" || r.Status == "FAILED" {
+			continue
+		}
+		if prefixContains(r.IpAddress, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixContains(s string, ip netip.Addr) bool {
+	if s == "" {
+		return false
+	}
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return err == nil && p.Contains(ip.Unmap())
+	}
+	a, err := netip.ParseAddr(s)
+	return err == nil && a.Unmap() == ip.Unmap()
+}
+
+// StartSessionRequest carries the session length as either DurationHours or
+// DurationMinutes; at most one of them should be set.
+type StartSessionRequest struct {
+	DurationHours   *int     `json:"durationHours,omitempty"`
+	DurationMinutes *int     `json:"durationMinutes,omitempty"`
+	Ipv4Address     string   `json:"ipv4Address,omitempty"`
+	Ipv6Address     string   `json:"ipv6Address,omitempty"`
+	ResourceIDs     []string `json:"resourceIds,omitempty"`
+	Ipv4PrefixLen   int      `json:"ipv4PrefixLength,omitempty"`
+	Ipv6PrefixLen   int      `json:"ipv6PrefixLength,omitempty"`
+	Reason          string   `json:"reason,omitempty"`
+	TicketRef       string   `json:"ticketRef,omitempty"`
+}
+
+// ExtendSessionRequest carries either AdditionalHours or AdditionalMinutes.
+type ExtendSessionRequest struct {
+	AdditionalHours   int `json:"additionalHours,omitempty"`
+	AdditionalMinutes int `json:"additionalMinutes,omitempty"`
+}
+
+// SessionPage is one page of a paginated session listing.
+type SessionPage struct {
+	Content       []Session `json:"content"`
+	Number        int       `json:"number"`
+	Size          int       `json:"size"`
+	TotalElements int       `json:"totalElements"`
+	TotalPages    int       `json:"totalPages"`
+	Last          bool      `json:"last"`
+}
+
+// SessionHistoryQuery filters the session history endpoint. Zero values are
+// omitted from the query string.
+type SessionHistoryQuery struct {
+	Since time.Time
+	Until time.Time
+	Page  int
+	Size  int
+}
+
+func (q SessionHistoryQuery) values() url.Values {
+	v := url.Values{}
+	if !q.Since.IsZero() {
+		v.Set("since", q.Since.UTC().Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		v.Set("until", q.Until.UTC().Format(time.RFC3339))
+	}
+	v.Set("page", strconv.Itoa(q.Page))
+	if q.Size > 0 {
+		v.Set("size", strconv.Itoa(q.Size))
+	}
+	return v
+}
+
+// StartSession whitelists the request's addresses for a while.
+func (c *Client) StartSession(ctx context.Context, req *StartSessionRequest) (*Session, error) {
+	var s Session
+	if err := c.do(ctx, "POST", "/sessions", req, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// StopSession ends one of the caller's sessions and revokes its rules.
+func (c *Client) StopSession(ctx context.Context, id string) (*Session, error) {
+	var s Session
+	if err := c.do(ctx, "POST", "/sessions/"+url.PathEscape(id)+"/stop", struct{}{}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSessions returns the caller's current sessions.
+func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	if err := c.do(ctx, "GET", "/sessions", nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetSession returns one of the caller's sessions by its full ID.
+func (c *Client) GetSession(ctx context.Context, id string) (*Session, error) {
+	var s Session
+	if err := c.do(ctx, "GET", "/sessions/"+url.PathEscape(id), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ExtendSession pushes back the expiry of one of the caller's active sessions.
+func (c *Client) ExtendSession(ctx context.Context, id string, req *ExtendSessionRequest) (*Session, error) {
+	var s Session
+	if err := c.do(ctx, "POST", "/sessions/"+url.PathEscape(id)+"/extend", req, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSessionHistory returns one page of past and current sessions.
+func (c *Client) ListSessionHistory(ctx context.Context, q SessionHistoryQuery) (*SessionPage, error) {
+	var page SessionPage
+	if err := c.do(ctx, "GET", "/sessions/history?"+q.values().Encode(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ListAllSessionHistory pages through the session history until the last
+// page, or until limit sessions have been collected when limit > 0.
+func (c *Client) ListAllSessionHistory(ctx context.Context, q SessionHistoryQuery, limit int) ([]Session, error) {
+	return allPages(q, limit, func(q SessionHistoryQuery) (*SessionPage, error) {
+		return c.ListSessionHistory(ctx, q)
+	})
+}
+
+// ListOrgSessionHistory returns one page of the past and current sessions of
+// every user in the organization. Requires org admin rights.
+func (c *Client) ListOrgSessionHistory(ctx context.Context, q SessionHistoryQuery) (*SessionPage, error) {
+	var page SessionPage
+	if err := c.do(ctx, "GET", "/admin/sessions/history?"+q.values().Encode(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// ListAllOrgSessionHistory is ListAllSessionHistory for the whole
+// organization. Requires org admin rights.
+func (c *Client) ListAllOrgSessionHistory(ctx context.Context, q SessionHistoryQuery, limit int) ([]Session, error) {
+	return allPages(q, limit, func(q SessionHistoryQuery) (*SessionPage, error) {
+		return c.ListOrgSessionHistory(ctx, q)
+	})
+}
+
+func allPages(q SessionHistoryQuery, limit int, fetch func(SessionHistoryQuery) (*SessionPage, error)) ([]Session, error) {
+	if q.Size <= 0 {
+		q.Size = 100
+	}
+	var sessions []Session
+	for {
+		page, err := fetch(q)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, page.Content...)
+		if limit > 0 && len(sessions) >= limit {
+			return sessions[:limit], nil
+		}
+		if page.Last || len(page.Content) == 0 || (page.TotalPages > 0 && q.Page+1 >= page.TotalPages) {
+			return sessions, nil
+		}
+		q.Page++
+	}
+}
+
+// ListOrgSessions returns the current sessions of every user in the
+// organization, optionally narrowed to one user (email or ID). Requires org
+// admin rights.
+func (c *Client) ListOrgSessions(ctx context.Context, user string) ([]Session, error) {
+	path := "/admin/sessions"
+	if user != "" {
+		path += "?" + url.Values{"user": {user}}.Encode()
+	}
+	var sessions []Session
+	if err := c.do(ctx, "GET", path, nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// StopOrgSession stops any user's session in the organization. Requires org
+// admin rights.
+func (c *Client) StopOrgSession(ctx context.Context, id string) (*Session, error) {
+	var s Session
+	if err := c.do(ctx, "POST", fmt.Sprintf("/admin/sessions/%s/stop", url.PathEscape(id)), struct{}{}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
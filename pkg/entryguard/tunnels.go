@@ -0,0 +1,91 @@
+package entryguard
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// DefaultTunnelHost is the tunnel edge of the EntryGuard cloud, for servers
+// that do not report one on their tunnel rules.
+const DefaultTunnelHost = "edge.entryguard.io"
+
+// tunnelPrefix starts the provider rule ID of every tunnel rule.
+const tunnelPrefix = "tunnel:"
+
+// ErrNotTunnel is returned by ParseTunnel for provider rule IDs that do not
+// belong to a tunnel resource.
+var ErrNotTunnel = errors.New("not a tunnel rule")
+
+// Tunnel is a rule on a tunnel resource: the edge port that forwards to the
+// resource, and the source CIDR allowed through it. Its provider rule ID is
+// "tunnel:<port>:<cidr>".
+type Tunnel struct {
+	Port int
+	CIDR netip.Prefix
+}
+
+// ParseTunnel decodes a tunnel provider rule ID. It returns ErrNotTunnel
+// if id lacks the "tunnel:" prefix, and a descriptive error if the port or
+// CIDR is invalid.
+func ParseTunnel(id string) (Tunnel, error) {
+	rest, ok := strings.CutPrefix(id, tunnelPrefix)
+	if !ok {
+		return Tunnel{}, ErrNotTunnel
+	}
+	// The CIDR may itself contain colons (IPv6), so split only once.
+	portText, cidrText, ok := strings.Cut(rest, ":")
+	if !ok {
+		return Tunnel{}, fmt.Errorf("invalid tunnel rule %q: want tunnel:<port>:<cidr>", id)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 1 || port > 65535 {
+		return Tunnel{}, fmt.Errorf("invalid tunnel rule %q: port %q is not in 1-65535", id, portText)
+	}
+	cidr, err := netip.ParsePrefix(cidrText)
+	if err != nil {
+		return Tunnel{}, fmt.Errorf("invalid tunnel rule %q: %w", id, err)
+	}
+	return Tunnel{Port: port, CIDR: cidr}, nil
+}
+
+// ValidateTunnelHost checks that host is a bare host name or IP address.
+// Tunnel hosts come from the server and end up on ssh command lines and in
+// ssh configs, so anything else is rejected: a leading '-', whitespace,
+// control characters, ports, paths and URL syntax.
+func ValidateTunnelHost(host string) error {
+	if host == "" {
+		return errors.New("empty tunnel host")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && addr.Zone() == "" {
+		return nil
+	}
+	if host[0] == '-' || host[0] == '.' {
+		return fmt.Errorf("invalid tunnel host %q: must start with a letter or digit", host)
+	}
+	for _, r := range host {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_'
+		if !ok {
+			return fmt.Errorf("invalid tunnel host %q: want a bare host name or IP address", host)
+		}
+	}
+	return nil
+}
+
+// String returns the provider rule ID of t.
+func (t Tunnel) String() string {
+	return tunnelPrefix + strconv.Itoa(t.Port) + ":" + t.CIDR.String()
+}
+
+// IsTunnel reports whether the rule belongs to a tunnel resource, whether
+// or not its provider rule ID is well formed.
+func (r *SessionResourceIp) IsTunnel() bool {
+	return strings.HasPrefix(r.ProviderRuleId, tunnelPrefix)
+}
+
+// Tunnel decodes the rule's provider rule ID; see ParseTunnel.
+func (r *SessionResourceIp) Tunnel() (Tunnel, error) {
+	return ParseTunnel(r.ProviderRuleId)
+}
@@ -0,0 +1,93 @@
+package entryguard_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/entryguard-io/cli/pkg/entryguard"
+	"github.com/entryguard-io/cli/pkg/entryguard/entryguardtest"
+)
+
+func TestParseTunnel(t *testing.T) {
+	for _, tc := range []struct {
+		id   string
+		want entryguard.Tunnel
+	}{
+		{"tunnel:41022:203.0.113.7/32", entryguard.Tunnel{Port: 41022, CIDR: netip.MustParsePrefix("203.0.113.7/32")}},
+		{"tunnel:22:2001:db8::1/128", entryguard.Tunnel{Port: 22, CIDR: netip.MustParsePrefix("2001:db8::1/128")}},
+	} {
+		got, err := entryguard.ParseTunnel(tc.id)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseTunnel(%q) = %+v, %v; want %+v", tc.id, got, err, tc.want)
+		}
+		if got.String() != tc.id {
+			t.Errorf("String() = %q, want %q", got.String(), tc.id)
+		}
+	}
+
+	for _, id := range []string{
+		"tunnel:",
+		"tunnel:41022",
+		"tunnel:0:203.0.113.7/32",
+		"tunnel:70000:203.0.113.7/32",
+		"tunnel:ssh:203.0.113.7/32",
+		"tunnel:41022:203.0.113.7",
+		"tunnel:41022:not-a-cidr",
+	} {
+		if _, err := entryguard.ParseTunnel(id); err == nil || errors.Is(err, entryguard.ErrNotTunnel) {
+			t.Errorf("ParseTunnel(%q) err = %v, want a validation error", id, err)
+		}
+	}
+	if _, err := entryguard.ParseTunnel("sgr-0123"); !errors.Is(err, entryguard.ErrNotTunnel) {
+		t.Errorf("ParseTunnel(sgr-0123) err = %v, want ErrNotTunnel", err)
+	}
+}
+
+func TestValidateTunnelHost(t *testing.T) {
+	for _, host := range []string{"edge.entryguard.io", "edge-eu_1.example.com", "127.0.0.1", "2001:db8::1"} {
+		if err := entryguard.ValidateTunnelHost(host); err != nil {
+			t.Errorf("ValidateTunnelHost(%q) = %v", host, err)
+		}
+	}
+	for _, host := range []string{
+		"",
+		"-oProxyCommand=touch /tmp/pwned",
+		"-edge",
+		"edge.example.com -v",
+		"edge\nexample.com",
+		"edge.example.com:22",
+		"https://edge.example.com",
+		"user@edge.example.com",
+		"fe80::1%eth0 -v",
+	} {
+		if err := entryguard.ValidateTunnelHost(host); err == nil {
+			t.Errorf("ValidateTunnelHost(%q) succeeded, want an error", host)
+		}
+	}
+}
+
+func TestTunnelSession(t *testing.T) {
+	srv := entryguardtest.NewServer()
+	defer srv.Close()
+	srv.ClientIP = "203.0.113.7"
+	srv.TunnelHost = "edge.eu.example.com"
+	srv.AddResource(entryguard.Resource{Name: "bastion", ResourceType: "TUNNEL", Enabled: true})
+
+	s, err := srv.Client().StartSession(context.Background(), &entryguard.StartSessionRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.ResourceIps) != 1 {
+		t.Fatalf("rules = %+v", s.ResourceIps)
+	}
+	r := s.ResourceIps[0]
+	tun, err := r.Tunnel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.IsTunnel() || tun.Port != entryguardtest.TunnelPortBase || tun.CIDR.String() != "203.0.113.7/32" || r.TunnelHost != "edge.eu.example.com" {
+		t.Errorf("tunnel rule = %+v (%+v)", r, tun)
+	}
+}
@@ -0,0 +1,43 @@
+package entryguard
+
+import "context"
+
+// UserInfo describes the authenticated user and their organization.
+type UserInfo struct {
+	ID               string `json:"id"`
+	Email            string `json:"email"`
+	Name             string `json:"name"`
+	IsOrgAdmin       bool   `json:"isOrgAdmin"`
+	PlatformRole     string `json:"platformRole"`
+	OrganizationID   string `json:"organizationId"`
+	OrganizationName string `json:"organizationName"`
+	OrganizationSlug string `json:"organizationSlug"`
+	SubscriptionTier string `json:"subscriptionTier"`
+	MfaEnabled       bool   `json:"mfaEnabled"`
+}
+
+// IpResponse is the caller's address as seen by the API.
+type IpResponse struct {
+	IP        string `json:"ip"`
+	Version   int    `json:"version"`
+	Timestamp string `json:"timestamp"`
+}
+
+// GetMe returns the authenticated user. It is a cheap way to validate
+// credentials.
+func (c *Client) GetMe(ctx context.Context) (*UserInfo, error) {
+	var u UserInfo
+	if err := c.do(ctx, "GET", "/auth/me", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// DetectIP returns the address the API sees the request coming from.
+func (c *Client) DetectIP(ctx context.Context) (*IpResponse, error) {
+	var ip IpResponse
+	if err := c.do(ctx, "GET", "/detect-ip", nil, &ip); err != nil {
+		return nil, err
+	}
+	return &ip, nil
+}